@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Handler returns a http.Handler to inspect and manage the registered caches. It serves:
+//
+//	GET    /caches                     every cache with its config, stats and last refresh status
+//	GET    /caches/{name}              a single cache
+//	GET    /caches/{name}/health       the health of a cache, with status 503 when it is down
+//	GET    /caches/{name}/keys/{key}   the cached record for key, without fetching it
+//	DELETE /caches/{name}/keys/{key}   invalidates the record for key
+//	DELETE /caches/{name}/tags/{tag}   invalidates every record carrying tag
+//	POST   /caches/{name}/refresh      reloads all records from the AsyncFetcher
+//
+// Keys are given as strings, or as JSON for other key types, e.g. 42 or {"Id":"a"}. Mount the handler with
+// http.StripPrefix to serve it below a path, and protect it as it exposes cached values.
+func (g *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /caches", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, g.Info())
+	})
+	mux.HandleFunc("GET /caches/{name}", g.handle(func(w http.ResponseWriter, req *http.Request, c registered) {
+		writeJSON(w, http.StatusOK, c.Info())
+	}))
+	mux.HandleFunc("GET /caches/{name}/health", g.handle(func(w http.ResponseWriter, req *http.Request, c registered) {
+		writeHealth(w, c.Health(req.Context()))
+	}))
+	mux.HandleFunc("GET /caches/{name}/keys/{key}", g.handle(func(w http.ResponseWriter, req *http.Request, c registered) {
+		e, ok, err := c.lookup(req.Context(), req.PathValue("key"))
+		if errors.Is(err, ErrDriverUnavailable) {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		} else if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !ok {
+			writeError(w, http.StatusNotFound, "key not cached")
+			return
+		}
+		writeJSON(w, http.StatusOK, e)
+	}))
+	mux.HandleFunc("DELETE /caches/{name}/keys/{key}", g.handle(func(w http.ResponseWriter, req *http.Request, c registered) {
+		ok, err := c.invalidate(req.Context(), req.PathValue("key"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"invalidated": ok})
+	}))
+	mux.HandleFunc("DELETE /caches/{name}/tags/{tag}", g.handle(func(w http.ResponseWriter, req *http.Request, c registered) {
+		writeJSON(w, http.StatusOK, map[string]int{"invalidated": c.InvalidateTag(req.Context(), req.PathValue("tag"))})
+	}))
+	mux.HandleFunc("POST /caches/{name}/refresh", g.handle(func(w http.ResponseWriter, req *http.Request, c registered) {
+		if err := c.Refresh(); errors.Is(err, ErrNoAsyncFetcher) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		} else if err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, c.Info().LastRefresh)
+	}))
+	return mux
+}
+
+// handle resolves the cache named in the request path before calling h, responding 404 when it is not registered.
+func (g *Registry) handle(h func(w http.ResponseWriter, req *http.Request, c registered)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		c, ok := g.get(req.PathValue("name"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "cache not registered")
+			return
+		}
+		h(w, req, c)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newAdminTestRegistry(t *testing.T) (*Registry, *RecordCache[int, string]) {
+	t.Helper()
+	reg := NewRegistry()
+	loads := 0
+	fetcher := AsyncFetcherFunc[int, string](func(context.Context) (map[int]string, error) {
+		loads++
+		if loads > 1 {
+			return nil, errors.New("source down")
+		}
+		return map[int]string{1: "one", 2: "two"}, nil
+	})
+	r := NewRecordCache[int, string](driver.NewMemoryCache[int, RecordCacheItem[string]]()).
+		SetAsyncFetcher(fetcher, time.Hour).
+		Register(reg, "numbers")
+	t.Cleanup(r.Close)
+	keyless := NewKeylessRecordCacheOnDemand[string](driver.NewMemoryCache[int, RecordCacheItem[string]](),
+		KeylessFetcherFunc[string](func(context.Context) (string, error) { return "token", nil }), time.Minute)
+	keyless.Register(reg, "token")
+	t.Cleanup(keyless.Close)
+	return reg, r
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	reg, _ := newAdminTestRegistry(t)
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "lists caches",
+			method:     http.MethodGet,
+			path:       "/caches",
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"numbers"`,
+		},
+		{
+			name:       "shows a cache",
+			method:     http.MethodGet,
+			path:       "/caches/numbers",
+			wantStatus: http.StatusOK,
+			wantBody:   `"records":2`,
+		},
+		{
+			name:       "unknown cache",
+			method:     http.MethodGet,
+			path:       "/caches/missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "looks up a key",
+			method:     http.MethodGet,
+			path:       "/caches/numbers/keys/1",
+			wantStatus: http.StatusOK,
+			wantBody:   `"value":"one"`,
+		},
+		{
+			name:       "key not cached",
+			method:     http.MethodGet,
+			path:       "/caches/numbers/keys/3",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid key",
+			method:     http.MethodGet,
+			path:       "/caches/numbers/keys/abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalidates a key",
+			method:     http.MethodDelete,
+			path:       "/caches/numbers/keys/2",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"invalidated":true}`,
+		},
+		{
+			name:       "failed refresh",
+			method:     http.MethodPost,
+			path:       "/caches/numbers/refresh",
+			wantStatus: http.StatusBadGateway,
+			wantBody:   "source down",
+		},
+		{
+			name:       "refresh without async fetcher",
+			method:     http.MethodPost,
+			path:       "/caches/token/refresh",
+			wantStatus: http.StatusConflict,
+		},
+	}
+	h := reg.Handler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %v, want it to contain %v", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRecordCache_Info(t *testing.T) {
+	reg, r := newAdminTestRegistry(t)
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"numbers", "token"}) {
+		t.Errorf("Names() = %v, want [numbers token]", got)
+	}
+	if err := r.Refresh(); err == nil {
+		t.Fatalf("Refresh() error = nil, want error")
+	}
+	info := r.Info()
+	if info.Name != "numbers" || !info.Ready || !info.Config.Async || info.Config.AsyncTtl != time.Hour {
+		t.Errorf("Info() = %+v, want ready async cache named numbers", info)
+	}
+	if !strings.Contains(info.LastRefresh.Error, "source down") || info.LastRefresh.Records != 2 {
+		t.Errorf("Info().LastRefresh = %+v, want failed refresh keeping 2 records", info.LastRefresh)
+	}
+	b, err := json.Marshal(reg.Info())
+	if err != nil || !strings.Contains(string(b), `"name":"token"`) {
+		t.Errorf("json.Marshal(Info()) = %s, %v", b, err)
+	}
+	reg.Unregister("token")
+	if got := reg.Names(); !reflect.DeepEqual(got, []string{"numbers"}) {
+		t.Errorf("Names() after Unregister() = %v, want [numbers]", got)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	type key struct{ Id string }
+	if got, err := ParseKey[string]("a b"); err != nil || got != "a b" {
+		t.Errorf("ParseKey[string]() = %v, %v", got, err)
+	}
+	if got, err := ParseKey[int]("42"); err != nil || got != 42 {
+		t.Errorf("ParseKey[int]() = %v, %v", got, err)
+	}
+	if got, err := ParseKey[key](`{"Id":"a"}`); err != nil || got != (key{Id: "a"}) {
+		t.Errorf("ParseKey[key]() = %v, %v", got, err)
+	}
+	if _, err := ParseKey[int]("x"); err == nil {
+		t.Errorf("ParseKey[int](x) error = nil, want error")
+	}
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"go.uber.org/zap"
+	"time"
+)
+
+// deltaCursorMeta is the name the delta cursor is persisted under in drivers implementing driver.MetaStore.
+const deltaCursorMeta = "delta_cursor"
+
+// Delta is the set of changes returned by a DeltaFetcher, and the cursor to request the following changes from.
+type Delta[K comparable, V any] struct {
+	Upserts map[K]V
+	Deletes []K
+	Cursor  string
+}
+
+// baselineCursor fetches the current cursor before a full load, so that changes made while the load is running are
+// applied by the next delta.
+func (r *RecordCache[K, V]) baselineCursor(ctx context.Context) (string, bool) {
+	if r.deltaFetcher == nil {
+		return "", false
+	}
+	release, err := r.acquireFetch(ctx)
+	if err != nil {
+		r.reportError(ctx, "Could not fetch delta cursor", err)
+		return "", false
+	}
+	defer release()
+	cursor, err := r.deltaFetcher.CurrentCursor(ctx)
+	if err != nil {
+		r.reportError(ctx, "Could not fetch delta cursor", err)
+		return "", false
+	}
+	return cursor, true
+}
+
+// refreshDelta applies the changes since the last cursor. It does nothing until a full load has established a cursor.
+func (r *RecordCache[K, V]) refreshDelta() error {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+	ctx := context.Background()
+	cursor := r.loadCursor(ctx)
+	if cursor == "" || !r.readiness.isReady() {
+		return nil
+	}
+	d, err := r.fetchSince(ctx, cursor)
+	if err != nil {
+		r.reportError(ctx, "Could not fetch delta", err)
+		return err
+	}
+	now := time.Now()
+	for k, v := range d.Upserts {
+		r.storeRecord(ctx, k, RecordCacheItem[V]{V: v, T: now, Async: true})
+	}
+	for _, k := range d.Deletes {
+		r.deleteRecord(ctx, k)
+	}
+	r.saveCursor(ctx, d.Cursor)
+	r.log.Debug("Delta applied", zap.Int("Upserts", len(d.Upserts)), zap.Int("Deletes", len(d.Deletes)))
+	return nil
+}
+
+func (r *RecordCache[K, V]) fetchSince(ctx context.Context, cursor string) (Delta[K, V], error) {
+	release, err := r.acquireFetch(ctx)
+	if err != nil {
+		return Delta[K, V]{}, err
+	}
+	defer release()
+	return r.deltaFetcher.FetchSince(ctx, cursor)
+}
+
+func (r *RecordCache[K, V]) loadCursor(ctx context.Context) string {
+	if m, ok := r.backend().(driver.MetaStore); ok {
+		if c, ok := m.GetMeta(ctx, deltaCursorMeta); ok {
+			return c
+		}
+	}
+	return r.cursor
+}
+
+func (r *RecordCache[K, V]) saveCursor(ctx context.Context, cursor string) {
+	r.cursor = cursor
+	if m, ok := r.backend().(driver.MetaStore); ok && !m.SetMeta(ctx, deltaCursorMeta, cursor) {
+		r.log.Warn("could not persist delta cursor")
+	}
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"go.uber.org/zap"
+	"testing"
+)
+
+type deltaFetcherMock struct {
+	cursors []string
+}
+
+func (d *deltaFetcherMock) FetchSince(_ context.Context, cursor string) (Delta[string, int], error) {
+	d.cursors = append(d.cursors, cursor)
+	return Delta[string, int]{
+		Upserts: map[string]int{"active1": 100, "new": 3},
+		Deletes: []string{"stale1"},
+		Cursor:  "2",
+	}, nil
+}
+
+func (d *deltaFetcherMock) CurrentCursor(_ context.Context) (string, error) {
+	return "1", nil
+}
+
+func TestRecordCache_refreshDelta(t *testing.T) {
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	f := &deltaFetcherMock{}
+	r := &RecordCache[string, int]{
+		log:          zap.NewNop(),
+		cache:        c,
+		asyncFetcher: newAsyncFetcherMock(),
+		deltaFetcher: f,
+	}
+
+	if err := r.refreshDelta(); err != nil || len(f.cursors) != 0 {
+		t.Fatalf("refreshDelta() before full load error = %v, calls = %v", err, f.cursors)
+	}
+	if err := r.refreshAsyncIfDue(); err != nil {
+		t.Fatalf("refreshAsyncIfDue() error = %v", err)
+	}
+	if got, _ := c.GetMeta(context.Background(), deltaCursorMeta); got != "1" {
+		t.Errorf("baseline cursor = %v, want %v", got, "1")
+	}
+	if err := r.refreshDelta(); err != nil {
+		t.Fatalf("refreshDelta() error = %v", err)
+	}
+	if got, _ := c.GetMeta(context.Background(), deltaCursorMeta); got != "2" {
+		t.Errorf("cursor after delta = %v, want %v", got, "2")
+	}
+	if v, _ := c.Get(context.Background(), "active1"); v.V != 100 || !v.Async {
+		t.Errorf("upserted record = %+v, want 100 async", v)
+	}
+	if !c.Has(context.Background(), "new") {
+		t.Errorf("inserted record missing")
+	}
+	if c.Has(context.Background(), "stale1") {
+		t.Errorf("deleted record still present")
+	}
+	if want := []string{"1"}; len(f.cursors) != 1 || f.cursors[0] != want[0] {
+		t.Errorf("FetchSince() cursors = %v, want %v", f.cursors, want)
+	}
+}
+
+func TestRecordCache_refreshDeltaAfterPartialMerge(t *testing.T) {
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	c.SetMeta(context.Background(), deltaCursorMeta, "0")
+	f := &deltaFetcherMock{}
+	r := &RecordCache[string, int]{
+		log:           zap.NewNop(),
+		cache:         c,
+		asyncFetcher:  newPartialAsyncFetcher[string, int](partialAsyncFetcherMock{}),
+		deltaFetcher:  f,
+		partialPolicy: PartialPolicyMerge,
+	}
+
+	if _, err := r.refreshAllRecords(); err != nil {
+		t.Fatalf("refreshAllRecords() error = %v", err)
+	}
+	if got, _ := c.GetMeta(context.Background(), deltaCursorMeta); got != "0" {
+		t.Errorf("cursor after partial merge = %v, want the previous cursor 0", got)
+	}
+}
@@ -0,0 +1,32 @@
+package cache
+
+import "errors"
+
+// ErrNotReady is returned by RecordCache.Get when the cache relies on an AsyncFetcher and the first load has not yet
+// completed.
+var ErrNotReady = errors.New("cache not ready")
+
+// ErrNotFound can be returned by fetchers when the requested record does not exist, so that a fallback chain can tell
+// it apart from a failure.
+var ErrNotFound = errors.New("record not found")
+
+// ErrFetchQueueFull is returned when a fetch cannot be queued because the wait queue configured by FetchLimits is
+// full.
+var ErrFetchQueueFull = errors.New("fetch queue full")
+
+// ErrFetchQueueTimeout is returned when a fetch waited in the queue for longer than FetchLimits.QueueTimeout.
+var ErrFetchQueueTimeout = errors.New("timed out waiting in fetch queue")
+
+// ErrNoWriter is returned by RecordCache.Set and RecordCache.Delete when no Writer has been set.
+var ErrNoWriter = errors.New("writer not set")
+
+// ErrClosed is returned by RecordCache.Set and RecordCache.Delete in write-behind mode once the cache has been closed,
+// as the write would never reach the source.
+var ErrClosed = errors.New("cache closed")
+
+// ErrNoAsyncFetcher is returned by RecordCache.Refresh when no AsyncFetcher has been set.
+var ErrNoAsyncFetcher = errors.New("async fetcher not set")
+
+// ErrDriverUnavailable is returned by RecordCache.Get when the driver failed to read the record, rather than fetching
+// it as for a miss. It wraps the driver error, and is only returned for drivers implementing driver.CacheV2.
+var ErrDriverUnavailable = errors.New("cache driver unavailable")
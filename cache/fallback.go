@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+)
+
+// NamedFetcher is an OnDemandFetcher with the name of its source, used in logs, stats and RecordCacheItem.Source.
+type NamedFetcher[K comparable, V any] struct {
+	Name    string
+	Fetcher OnDemandFetcher[K, V]
+}
+
+// FallbackFetcher tries an ordered list of fetchers, moving on to the next one when the previous fails or returns
+// ErrNotFound.
+type FallbackFetcher[K comparable, V any] struct {
+	log      *zap.Logger
+	fetchers []NamedFetcher[K, V]
+	// cacheLog returns the logger of the RecordCache the fetcher was set on by SetFallbackFetchers, so that a logger
+	// added to the cache afterwards is used
+	cacheLog func() *zap.Logger
+}
+
+func NewFallbackFetcher[K comparable, V any](fetchers ...NamedFetcher[K, V]) *FallbackFetcher[K, V] {
+	return &FallbackFetcher[K, V]{
+		log:      zap.NewNop(),
+		fetchers: fetchers,
+	}
+}
+
+func (f *FallbackFetcher[K, V]) AddLogger(l *zap.Logger) *FallbackFetcher[K, V] {
+	f.log = l
+	f.cacheLog = nil
+	return f
+}
+
+func (f *FallbackFetcher[K, V]) logger() *zap.Logger {
+	if f.cacheLog != nil {
+		return f.cacheLog()
+	}
+	return f.log
+}
+
+func (f *FallbackFetcher[K, V]) FetchByKey(ctx context.Context, k K) (V, error) {
+	v, _, err := f.FetchByKeyWithSource(ctx, k)
+	return v, err
+}
+
+// FetchByKeyWithSource returns the record from the first fetcher able to serve it, along with that fetcher's name.
+// If every fetcher fails, the joined errors are returned; they match ErrNotFound only if every fetcher returned it.
+// Once ctx is done no further fetcher is tried, and the context error is returned along with the previous errors.
+func (f *FallbackFetcher[K, V]) FetchByKeyWithSource(ctx context.Context, k K) (V, string, error) {
+	errs := make([]error, 0, len(f.fetchers))
+	notFound := true
+	for _, nf := range f.fetchers {
+		if err := ctx.Err(); err != nil {
+			return *new(V), "", errors.Join(append(errs, err)...)
+		}
+		v, err := nf.Fetcher.FetchByKey(ctx, k)
+		if err == nil {
+			return v, nf.Name, nil
+		}
+		f.logger().Warn("Fetcher failed, trying next", zap.String("Source", nf.Name), zap.Any("Key", k), zap.Error(err))
+		if errors.Is(err, ErrNotFound) {
+			// not wrapped, so the joined error only matches ErrNotFound when every fetcher returned it
+			errs = append(errs, fmt.Errorf("%s: %v", nf.Name, err))
+			continue
+		}
+		notFound = false
+		errs = append(errs, fmt.Errorf("%s: %w", nf.Name, err))
+	}
+	if len(errs) == 0 {
+		return *new(V), "", fmt.Errorf("no fetchers configured")
+	}
+	if notFound {
+		errs = append(errs, ErrNotFound)
+	}
+	return *new(V), "", errors.Join(errs...)
+}
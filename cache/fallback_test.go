@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+type namedFetcherMock struct {
+	v   int
+	err error
+}
+
+func (n namedFetcherMock) FetchByKey(_ context.Context, _ string) (int, error) {
+	return n.v, n.err
+}
+
+func TestFallbackFetcher_FetchByKeyWithSource(t *testing.T) {
+	tests := []struct {
+		name         string
+		fetchers     []NamedFetcher[string, int]
+		want         int
+		wantSource   string
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name: "first fetcher serves record",
+			fetchers: []NamedFetcher[string, int]{
+				{Name: "primary", Fetcher: namedFetcherMock{v: 1}},
+				{Name: "replica", Fetcher: namedFetcherMock{v: 2}},
+			},
+			want:       1,
+			wantSource: "primary",
+		},
+		{
+			name: "falls back on error",
+			fetchers: []NamedFetcher[string, int]{
+				{Name: "primary", Fetcher: namedFetcherMock{err: fmt.Errorf("error")}},
+				{Name: "replica", Fetcher: namedFetcherMock{v: 2}},
+			},
+			want:       2,
+			wantSource: "replica",
+		},
+		{
+			name: "falls back on not found",
+			fetchers: []NamedFetcher[string, int]{
+				{Name: "primary", Fetcher: namedFetcherMock{err: ErrNotFound}},
+				{Name: "defaults", Fetcher: namedFetcherMock{v: 3}},
+			},
+			want:       3,
+			wantSource: "defaults",
+		},
+		{
+			name: "not found by every fetcher",
+			fetchers: []NamedFetcher[string, int]{
+				{Name: "primary", Fetcher: namedFetcherMock{err: ErrNotFound}},
+				{Name: "replica", Fetcher: namedFetcherMock{err: ErrNotFound}},
+			},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "failure by any fetcher is not a not found",
+			fetchers: []NamedFetcher[string, int]{
+				{Name: "primary", Fetcher: namedFetcherMock{err: fmt.Errorf("error")}},
+				{Name: "replica", Fetcher: namedFetcherMock{err: ErrNotFound}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, source, err := NewFallbackFetcher(tt.fetchers...).FetchByKeyWithSource(context.Background(), "k")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchByKeyWithSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("FetchByKeyWithSource() error = %v, wantNotFound %v", err, tt.wantNotFound)
+			}
+			if got != tt.want || source != tt.wantSource {
+				t.Errorf("FetchByKeyWithSource() = %v, %v, want %v, %v", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestRecordCache_SetFallbackFetchers(t *testing.T) {
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	r := NewRecordCache[string, int](c).SetFallbackFetchers(time.Minute,
+		NamedFetcher[string, int]{Name: "primary", Fetcher: namedFetcherMock{err: fmt.Errorf("error")}},
+		NamedFetcher[string, int]{Name: "replica", Fetcher: namedFetcherMock{v: 2}},
+	)
+	defer r.Close()
+	got, err := r.Get(context.Background(), "k")
+	if err != nil || got != 2 {
+		t.Fatalf("Get() = %v, %v, want 2, nil", got, err)
+	}
+	if item, _ := c.Get(context.Background(), "k"); item.Source != "replica" {
+		t.Errorf("cached item Source = %v, want replica", item.Source)
+	}
+	if _, err = r.Get(context.Background(), "k"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	stats := r.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Fetches != 1 || stats.Sources["replica"] != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestFallbackFetcher_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	called := false
+	f := NewFallbackFetcher(
+		NamedFetcher[string, int]{Name: "primary", Fetcher: OnDemandFetcherFunc[string, int](func(context.Context, string) (int, error) {
+			cancel()
+			return 0, fmt.Errorf("error")
+		})},
+		NamedFetcher[string, int]{Name: "replica", Fetcher: OnDemandFetcherFunc[string, int](func(context.Context, string) (int, error) {
+			called = true
+			return 2, nil
+		})},
+	)
+	if _, _, err := f.FetchByKeyWithSource(ctx, "k"); !errors.Is(err, context.Canceled) {
+		t.Errorf("FetchByKeyWithSource() error = %v, want %v", err, context.Canceled)
+	}
+	if called {
+		t.Errorf("fallback fetcher called after the context was cancelled")
+	}
+}
+
+func TestRecordCache_SetFallbackFetchers_logger(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+		SetFallbackFetchers(time.Minute,
+			NamedFetcher[string, int]{Name: "primary", Fetcher: namedFetcherMock{err: fmt.Errorf("error")}},
+			NamedFetcher[string, int]{Name: "replica", Fetcher: namedFetcherMock{v: 2}},
+		).
+		AddLogger(zap.New(core))
+	defer r.Close()
+	if _, err := r.Get(context.Background(), "k"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if n := logs.FilterMessage("Fetcher failed, trying next").Len(); n != 1 {
+		t.Errorf("fallback warnings logged by the cache logger = %d, want 1", n)
+	}
+}
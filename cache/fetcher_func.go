@@ -0,0 +1,24 @@
+package cache
+
+import "context"
+
+// OnDemandFetcherFunc allows a plain function to be used as an OnDemandFetcher.
+type OnDemandFetcherFunc[K comparable, V any] func(ctx context.Context, k K) (V, error)
+
+func (f OnDemandFetcherFunc[K, V]) FetchByKey(ctx context.Context, k K) (V, error) {
+	return f(ctx, k)
+}
+
+// AsyncFetcherFunc allows a plain function to be used as an AsyncFetcher.
+type AsyncFetcherFunc[K comparable, V any] func(ctx context.Context) (map[K]V, error)
+
+func (f AsyncFetcherFunc[K, V]) FetchAll(ctx context.Context) (map[K]V, error) {
+	return f(ctx)
+}
+
+// KeylessFetcherFunc allows a plain function to be used as a KeylessFetcher.
+type KeylessFetcherFunc[V any] func(ctx context.Context) (V, error)
+
+func (f KeylessFetcherFunc[V]) Fetch(ctx context.Context) (V, error) {
+	return f(ctx)
+}
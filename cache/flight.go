@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// flight is a fetch shared by every caller requesting the same key while it runs.
+type flight[V any] struct {
+	done chan struct{}
+	item RecordCacheItem[V]
+	err  error
+	// mu guards invalidated, and is held while the result is stored so that invalidate waits for a store in progress.
+	mu          sync.Mutex
+	invalidated bool
+}
+
+// store runs fn to store the result of the flight, unless the flight has been invalidated by a write to the key.
+func (f *flight[V]) store(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.invalidated {
+		fn()
+	}
+}
+
+// flightGroup coalesces concurrent fetches of the same key. The zero value is ready to use.
+type flightGroup[K comparable, V any] struct {
+	mu sync.Mutex
+	m  map[K]*flight[V]
+}
+
+// do returns the flight in progress for k, or starts fn in a new goroutine. The fetch runs to completion regardless
+// of whether any caller is still waiting for it. fn should store its result with flight.store.
+func (g *flightGroup[K, V]) do(k K, fn func(f *flight[V]) (RecordCacheItem[V], error)) *flight[V] {
+	g.mu.Lock()
+	if f, ok := g.m[k]; ok {
+		g.mu.Unlock()
+		return f
+	}
+	if g.m == nil {
+		g.m = map[K]*flight[V]{}
+	}
+	f := &flight[V]{done: make(chan struct{})}
+	g.m[k] = f
+	g.mu.Unlock()
+
+	go func() {
+		f.item, f.err = fn(f)
+		g.mu.Lock()
+		if g.m[k] == f {
+			delete(g.m, k)
+		}
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	return f
+}
+
+// invalidate stops the flight in progress for k, if any, from storing its result, as it may have been fetched before
+// the source was written. Callers requesting k afterwards start a new flight. Waits for a store in progress.
+func (g *flightGroup[K, V]) invalidate(k K) {
+	g.mu.Lock()
+	f, ok := g.m[k]
+	if ok {
+		delete(g.m, k)
+	}
+	g.mu.Unlock()
+	if ok {
+		f.mu.Lock()
+		f.invalidated = true
+		f.mu.Unlock()
+	}
+}
+
+// defaultFetchTimeout bounds on demand fetches when no fetch timeout is set and the request has no deadline, so that
+// a hung source cannot hold a flight, and every caller joining it, forever.
+const defaultFetchTimeout = time.Minute
+
+// fetchContext returns a context for a fetch started by a request with ctx. It keeps the values of ctx, such as trace
+// ids, but not its cancellation. It is bounded by the fetch timeout or, when none is set, by the deadline of ctx or
+// defaultFetchTimeout.
+func (r *RecordCache[K, V]) fetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, hasDeadline := ctx.Deadline()
+	ctx = context.WithoutCancel(ctx)
+	switch {
+	case r.fetchTimeout > 0:
+		return context.WithTimeout(ctx, r.fetchTimeout)
+	case hasDeadline:
+		return context.WithDeadline(ctx, deadline)
+	}
+	return context.WithTimeout(ctx, defaultFetchTimeout)
+}
+
+// SetFetchTimeout bounds the duration of on demand fetches. Fetches are detached from the request which started them,
+// so they complete and populate the cache for every waiting caller even if the first caller gives up. Without a fetch
+// timeout, fetches are bounded by the deadline of the request which started them, or by one minute when it has none.
+func (r *RecordCache[K, V]) SetFetchTimeout(d time.Duration) *RecordCache[K, V] {
+	r.fetchTimeout = d
+	return r
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestRecordCache_detachedFetch(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	var gotValue atomic.Value
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	r := NewRecordCache[string, int](c).
+		SetOnDemandFetcher(OnDemandFetcherFunc[string, int](func(ctx context.Context, _ string) (int, error) {
+			calls.Add(1)
+			gotValue.Store(ctx.Value(ctxKey{}))
+			close(started)
+			<-unblock
+			return 1, ctx.Err()
+		}), time.Minute)
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "trace"))
+	first := make(chan error)
+	go func() {
+		_, err := r.Get(ctx, "k")
+		first <- err
+	}()
+	<-started
+	second := make(chan int)
+	go func() {
+		v, _ := r.Get(context.Background(), "k")
+		second <- v
+	}()
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Get() error = %v, want %v", err, context.Canceled)
+	}
+	close(unblock)
+	if v := <-second; v != 1 {
+		t.Errorf("waiting Get() = %v, want 1", v)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fetch calls = %v, want 1", n)
+	}
+	if v := gotValue.Load(); v != "trace" {
+		t.Errorf("fetch context value = %v, want trace", v)
+	}
+	if !c.Has(context.Background(), "k") {
+		t.Errorf("detached fetch did not populate cache")
+	}
+}
+
+func TestRecordCache_SetFetchTimeout(t *testing.T) {
+	r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+		SetFetchTimeout(time.Millisecond).
+		SetOnDemandFetcher(OnDemandFetcherFunc[string, int](func(ctx context.Context, _ string) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}), time.Minute)
+	defer r.Close()
+	if _, err := r.Get(context.Background(), "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRecordCache_fetchContext(t *testing.T) {
+	r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]())
+	callerDeadline := time.Now().Add(time.Hour)
+	withDeadline, cancelCaller := context.WithDeadline(context.Background(), callerDeadline)
+	defer cancelCaller()
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		ctx     context.Context
+		want    time.Time
+	}{
+		{name: "fetch timeout", timeout: time.Second, ctx: withDeadline, want: time.Now().Add(time.Second)},
+		{name: "caller deadline", ctx: withDeadline, want: callerDeadline},
+		{name: "default timeout", ctx: context.Background(), want: time.Now().Add(defaultFetchTimeout)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.fetchTimeout = tt.timeout
+			ctx, cancel := r.fetchContext(tt.ctx)
+			defer cancel()
+			got, ok := ctx.Deadline()
+			if !ok || got.Sub(tt.want).Abs() > time.Second {
+				t.Errorf("Deadline() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+	cancelCaller()
+	ctx, cancel := r.fetchContext(withDeadline)
+	defer cancel()
+	if ctx.Err() != nil {
+		t.Errorf("fetch context cancelled with the caller: %v", ctx.Err())
+	}
+}
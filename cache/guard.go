@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"strconv"
+	"time"
+)
+
+// asyncCountMeta is the name the size of the last full load is persisted under in drivers implementing
+// driver.MetaStore, so that MaxShrinkPercent applies to the first load after a restart.
+const asyncCountMeta = "async_count"
+
+// maxRejectionBackoff caps the doubling of the wait after consecutive rejected loads.
+const maxRejectionBackoff = 6
+
+// RefreshGuard validates the result of a full async load before it replaces the existing dataset. A rejected load
+// keeps the existing dataset and is reported as a *RefreshRejectedError.
+type RefreshGuard[K comparable, V any] struct {
+	// Validate is an optional validator for the records about to replace the dataset.
+	Validate func(latest map[K]V) error
+	// MinRecords is the minimum number of records a load must return. Zero disables the check.
+	MinRecords int
+	// MaxShrinkPercent is the maximum percentage (0-100) the dataset may shrink by compared to the previous load.
+	// Zero disables the check.
+	MaxShrinkPercent float64
+}
+
+// check returns a *RefreshRejectedError when latest violates the guard, previous being the size of the previous load.
+func (g *RefreshGuard[K, V]) check(latest map[K]V, previous int) error {
+	if g.MinRecords > 0 && len(latest) < g.MinRecords {
+		return &RefreshRejectedError{
+			Reason:   fmt.Sprintf("%d records is below the minimum of %d", len(latest), g.MinRecords),
+			Records:  len(latest),
+			Previous: previous,
+		}
+	}
+	if g.MaxShrinkPercent > 0 && previous > 0 && len(latest) < previous {
+		shrink := float64(previous-len(latest)) / float64(previous) * 100
+		if shrink > g.MaxShrinkPercent {
+			return &RefreshRejectedError{
+				Reason:   fmt.Sprintf("dataset shrank by %.1f%%, more than the maximum of %.1f%%", shrink, g.MaxShrinkPercent),
+				Records:  len(latest),
+				Previous: previous,
+			}
+		}
+	}
+	if g.Validate != nil {
+		if err := g.Validate(latest); err != nil {
+			return &RefreshRejectedError{Reason: "validation failed", Records: len(latest), Previous: previous, Err: err}
+		}
+	}
+	return nil
+}
+
+// RefreshRejectedError is reported when a RefreshGuard rejects an async load.
+type RefreshRejectedError struct {
+	Reason   string
+	Records  int
+	Previous int
+	Err      error
+}
+
+func (e *RefreshRejectedError) Error() string {
+	msg := "refresh rejected: " + e.Reason
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *RefreshRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// previousAsyncCount returns the size of the previous full load. Before the first load of this process, it is read
+// from the driver when persisted, or counted from the async records already cached, so that a persistent driver keeps
+// its dataset when the first load after a restart is broken.
+func (r *RecordCache[K, V]) previousAsyncCount(ctx context.Context) int {
+	if r.asyncCount > 0 || r.guard == nil || r.guard.MaxShrinkPercent <= 0 {
+		return r.asyncCount
+	}
+	if m, ok := r.backend().(driver.MetaStore); ok {
+		if v, ok := m.GetMeta(ctx, asyncCountMeta); ok {
+			if n, err := strconv.Atoi(v); err == nil {
+				return n
+			}
+		}
+	}
+	n := 0
+	for _, item := range r.allRecords(ctx) {
+		if item.Async || r.onDemandFetcher == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// saveAsyncCount records the size of a full load which replaced the dataset.
+func (r *RecordCache[K, V]) saveAsyncCount(ctx context.Context, n int) {
+	r.asyncCount = n
+	if r.guard == nil {
+		return
+	}
+	if m, ok := r.backend().(driver.MetaStore); ok && !m.SetMeta(ctx, asyncCountMeta, strconv.Itoa(n)) {
+		r.log.Warn("could not persist async record count")
+	}
+}
+
+// backOffRejection delays the next scheduled load after a load rejected by the guard, doubling the wait for each
+// consecutive rejection up to the async ttl, so that a broken source is not reloaded in full every minute. Successful
+// loads reset it. The caller must hold refreshMu.
+func (r *RecordCache[K, V]) backOffRejection(err error) {
+	var rejected *RefreshRejectedError
+	if err == nil || !errors.As(err, &rejected) {
+		r.rejections = 0
+		r.retryAt = time.Time{}
+		return
+	}
+	r.rejections++
+	backoff := asyncCacheCheckFrequency << min(r.rejections, maxRejectionBackoff)
+	if r.allTtl > asyncCacheCheckFrequency {
+		backoff = min(backoff, r.allTtl)
+	}
+	r.retryAt = time.Now().Add(backoff)
+	r.lastRejection = err
+}
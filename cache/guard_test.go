@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRefreshGuard_check(t *testing.T) {
+	tests := []struct {
+		name     string
+		guard    RefreshGuard[string, int]
+		latest   map[string]int
+		previous int
+		wantErr  bool
+	}{
+		{
+			name:   "empty guard accepts empty load",
+			guard:  RefreshGuard[string, int]{},
+			latest: map[string]int{},
+		},
+		{
+			name:    "below minimum records rejected",
+			guard:   RefreshGuard[string, int]{MinRecords: 2},
+			latest:  map[string]int{"a": 1},
+			wantErr: true,
+		},
+		{
+			name:     "shrink within maximum accepted",
+			guard:    RefreshGuard[string, int]{MaxShrinkPercent: 50},
+			latest:   map[string]int{"a": 1, "b": 2},
+			previous: 4,
+		},
+		{
+			name:     "shrink above maximum rejected",
+			guard:    RefreshGuard[string, int]{MaxShrinkPercent: 50},
+			latest:   map[string]int{"a": 1},
+			previous: 4,
+			wantErr:  true,
+		},
+		{
+			name:     "shrink ignored on first load",
+			guard:    RefreshGuard[string, int]{MaxShrinkPercent: 10},
+			latest:   map[string]int{},
+			previous: 0,
+		},
+		{
+			name: "validator error rejected",
+			guard: RefreshGuard[string, int]{Validate: func(map[string]int) error {
+				return fmt.Errorf("invalid")
+			}},
+			latest:  map[string]int{"a": 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.guard.check(tt.latest, tt.previous)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var rejected *RefreshRejectedError
+			if tt.wantErr && !errors.As(err, &rejected) {
+				t.Errorf("check() error = %T, want *RefreshRejectedError", err)
+			}
+		})
+	}
+}
+
+type emptyAsyncFetcherMock struct{}
+
+func (e emptyAsyncFetcherMock) FetchAll(_ context.Context) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func TestRecordCache_SetRefreshGuard(t *testing.T) {
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	c.Set(context.Background(), "active1", RecordCacheItem[int]{V: 1, T: time.Now(), Async: true})
+	var hooked error
+	r := &RecordCache[string, int]{
+		log:          zap.NewNop(),
+		cache:        c,
+		asyncFetcher: emptyAsyncFetcherMock{},
+		asyncCount:   1,
+		errorHooks:   []ErrorHook{func(_ context.Context, err error) { hooked = err }},
+	}
+	r.SetRefreshGuard(RefreshGuard[string, int]{MinRecords: 1})
+	if _, err := r.refreshAllRecords(); err == nil {
+		t.Fatalf("refreshAllRecords() expected error for empty load")
+	}
+	var rejected *RefreshRejectedError
+	if !errors.As(hooked, &rejected) {
+		t.Errorf("error hook got = %v, want *RefreshRejectedError", hooked)
+	}
+	if !c.Has(context.Background(), "active1") {
+		t.Errorf("existing record removed by rejected refresh")
+	}
+}
+
+func TestRecordCache_SetRefreshGuard_afterRestart(t *testing.T) {
+	tests := []struct {
+		name    string
+		persist bool
+	}{
+		{
+			name:    "previous count persisted in driver",
+			persist: true,
+		},
+		{
+			name: "previous count counted from cached records",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+			for _, k := range []string{"a", "b", "c", "d"} {
+				c.Set(context.Background(), k, RecordCacheItem[int]{V: 1, T: time.Now(), Async: true})
+			}
+			if tt.persist {
+				c.SetMeta(context.Background(), asyncCountMeta, "4")
+			}
+			r := &RecordCache[string, int]{
+				log:   zap.NewNop(),
+				cache: c,
+				asyncFetcher: AsyncFetcherFunc[string, int](func(context.Context) (map[string]int, error) {
+					return map[string]int{"a": 1}, nil
+				}),
+			}
+			r.SetRefreshGuard(RefreshGuard[string, int]{MaxShrinkPercent: 50})
+			var rejected *RefreshRejectedError
+			if _, err := r.refreshAllRecords(); !errors.As(err, &rejected) || rejected.Previous != 4 {
+				t.Fatalf("refreshAllRecords() error = %v, want rejection against 4 records", err)
+			}
+			if !c.Has(context.Background(), "d") {
+				t.Errorf("existing record removed by rejected refresh")
+			}
+		})
+	}
+}
+
+func TestRecordCache_SetRefreshGuard_backoff(t *testing.T) {
+	fetches := 0
+	r := &RecordCache[string, int]{
+		log:    zap.NewNop(),
+		cache:  driver.NewMemoryCache[string, RecordCacheItem[int]](),
+		allTtl: time.Hour,
+		asyncFetcher: AsyncFetcherFunc[string, int](func(context.Context) (map[string]int, error) {
+			fetches++
+			return map[string]int{}, nil
+		}),
+	}
+	r.SetRefreshGuard(RefreshGuard[string, int]{MinRecords: 1})
+
+	for range 3 {
+		if err := r.refreshAsyncIfDue(); err == nil {
+			t.Fatalf("refreshAsyncIfDue() expected error for rejected load")
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches while backed off = %d, want 1", fetches)
+	}
+	if wait := time.Until(r.nextRetry()); wait <= asyncCacheCheckFrequency || wait > 2*asyncCacheCheckFrequency {
+		t.Errorf("backoff after first rejection = %v, want 2 minutes", wait)
+	}
+
+	r.retryAt = time.Now()
+	_ = r.refreshAsyncIfDue()
+	if wait := time.Until(r.nextRetry()); fetches != 2 || wait <= 2*asyncCacheCheckFrequency {
+		t.Errorf("after second rejection fetches = %d, backoff = %v, want 2 and 4 minutes", fetches, wait)
+	}
+
+	r.retryAt = time.Now()
+	r.guard = nil
+	if err := r.refreshAsyncIfDue(); err != nil || !r.nextRetry().IsZero() || r.rejections != 0 {
+		t.Errorf("successful refresh error = %v, retryAt = %v, rejections = %d, want backoff reset", err, r.nextRetry(), r.rejections)
+	}
+}
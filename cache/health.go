@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthStatus is the outcome of a health check. A degraded cache still serves records, while a down cache cannot be
+// relied upon.
+type HealthStatus string
+
+const (
+	HealthUp       HealthStatus = "up"
+	HealthDegraded HealthStatus = "degraded"
+	HealthDown     HealthStatus = "down"
+)
+
+// Health is the health of a cache, the worst status of its checks.
+type Health struct {
+	Name   string        `json:"name"`
+	Status HealthStatus  `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the outcome of a single check of a cache.
+type HealthCheck struct {
+	Name    string       `json:"name"`
+	Status  HealthStatus `json:"status"`
+	Message string       `json:"message,omitempty"`
+}
+
+// HealthConfig sets the thresholds of the health checks. Zero values use the defaults.
+type HealthConfig struct {
+	// MaxRefreshAge is how many async ttls may pass since the last successful refresh before the cache is down.
+	// Defaults to 3.
+	MaxRefreshAge int
+	// MaxErrorRate is the fraction of on demand fetches which may fail within ErrorRateWindow before the cache is
+	// degraded. Defaults to 0.5.
+	MaxErrorRate float64
+	// ErrorRateWindow is the period over which the fetch error rate is measured. Defaults to 5 minutes.
+	ErrorRateWindow time.Duration
+	// MinFetches is the number of fetches required within the window for the error rate to be checked. Defaults to
+	// 10.
+	MinFetches int
+	// PingTimeout bounds how long the driver ping may take. Defaults to 2 seconds.
+	PingTimeout time.Duration
+}
+
+func (c HealthConfig) withDefaults() HealthConfig {
+	if c.MaxRefreshAge <= 0 {
+		c.MaxRefreshAge = 3
+	}
+	if c.MaxErrorRate <= 0 {
+		c.MaxErrorRate = 0.5
+	}
+	if c.ErrorRateWindow <= 0 {
+		c.ErrorRateWindow = 5 * time.Minute
+	}
+	if c.MinFetches <= 0 {
+		c.MinFetches = 10
+	}
+	if c.PingTimeout <= 0 {
+		c.PingTimeout = 2 * time.Second
+	}
+	return c
+}
+
+// SetHealthConfig sets the thresholds used by Health.
+func (r *RecordCache[K, V]) SetHealthConfig(c HealthConfig) *RecordCache[K, V] {
+	r.healthConfig = c
+	return r
+}
+
+// Health checks that the driver is reachable when it implements driver.Pinger, that the last async refresh succeeded
+// recently enough, and that the on demand fetch error rate is below the threshold.
+func (r *RecordCache[K, V]) Health(ctx context.Context) Health {
+	c := r.healthConfig.withDefaults()
+	h := Health{Name: r.name, Status: HealthUp}
+	if p, ok := r.backend().(driver.Pinger); ok {
+		h.add(r.checkDriver(ctx, p, c))
+	}
+	if r.asyncFetcher != nil {
+		h.add(r.checkRefresh(c))
+	}
+	if r.onDemandFetcher != nil {
+		h.add(r.checkFetchErrors(c))
+	}
+	return h
+}
+
+// HealthHandler returns a http.Handler responding with the health of the cache as JSON, with status 503 when the
+// cache is down and 200 otherwise. It is suitable for readiness probes and monitoring only: the cache is down while the
+// driver or the source is unreachable, which restarting the process would not fix, so it must not be used as a
+// liveness probe.
+func (r *RecordCache[K, V]) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeHealth(w, r.Health(req.Context()))
+	})
+}
+
+func writeHealth(w http.ResponseWriter, h Health) {
+	status := http.StatusOK
+	if h.Status == HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, h)
+}
+
+func (h *Health) add(c HealthCheck) {
+	h.Checks = append(h.Checks, c)
+	if c.Status == HealthDown || (c.Status == HealthDegraded && h.Status == HealthUp) {
+		h.Status = c.Status
+	}
+}
+
+func (r *RecordCache[K, V]) checkDriver(ctx context.Context, p driver.Pinger, c HealthConfig) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, c.PingTimeout)
+	defer cancel()
+	if err := p.Ping(ctx); err != nil {
+		return HealthCheck{Name: "driver", Status: HealthDown, Message: err.Error()}
+	}
+	return HealthCheck{Name: "driver", Status: HealthUp}
+}
+
+func (r *RecordCache[K, V]) checkRefresh(c HealthConfig) HealthCheck {
+	r.statusMu.Lock()
+	last, lastSuccess := r.lastRefresh, r.lastSuccess
+	r.statusMu.Unlock()
+	maxAge := time.Duration(c.MaxRefreshAge) * r.allTtl
+	switch {
+	case lastSuccess.IsZero():
+		return HealthCheck{Name: "refresh", Status: HealthDown, Message: "first load not complete"}
+	case time.Since(lastSuccess) > maxAge:
+		return HealthCheck{Name: "refresh", Status: HealthDown,
+			Message: fmt.Sprintf("last successful refresh %s ago, over %s", time.Since(lastSuccess).Truncate(time.Second), maxAge)}
+	case last.Partial:
+		return HealthCheck{Name: "refresh", Status: HealthDegraded, Message: "last refresh was partial: " + last.Error}
+	case last.Error != "":
+		return HealthCheck{Name: "refresh", Status: HealthDegraded, Message: "last refresh failed: " + last.Error}
+	}
+	return HealthCheck{Name: "refresh", Status: HealthUp}
+}
+
+func (r *RecordCache[K, V]) checkFetchErrors(c HealthConfig) HealthCheck {
+	total, failed := r.fetchWindow.counts(time.Now(), c.ErrorRateWindow)
+	if total < uint64(c.MinFetches) {
+		return HealthCheck{Name: "fetch_errors", Status: HealthUp}
+	}
+	rate := float64(failed) / float64(total)
+	if rate > c.MaxErrorRate {
+		return HealthCheck{Name: "fetch_errors", Status: HealthDegraded,
+			Message: fmt.Sprintf("%d of %d fetches failed in the last %s", failed, total, c.ErrorRateWindow)}
+	}
+	return HealthCheck{Name: "fetch_errors", Status: HealthUp}
+}
+
+// observeFetch records the outcome of an on demand fetch for the error rate check. ErrNotFound is not a failure.
+func (r *RecordCache[K, V]) observeFetch(err error) {
+	r.fetchWindow.observe(time.Now(), r.healthConfig.withDefaults().ErrorRateWindow, err != nil && !errors.Is(err, ErrNotFound))
+}
+
+const errorWindowBuckets = 10
+
+// errorWindow counts outcomes over a sliding window, split into buckets so that old outcomes expire.
+type errorWindow struct {
+	mu      sync.Mutex
+	buckets [errorWindowBuckets]windowBucket
+}
+
+type windowBucket struct {
+	start  time.Time
+	total  uint64
+	failed uint64
+}
+
+// bucketWidth returns the width of each bucket of window, at least a nanosecond so that tiny windows do not divide by
+// zero.
+func bucketWidth(window time.Duration) time.Duration {
+	return max(window/errorWindowBuckets, 1)
+}
+
+func (w *errorWindow) observe(now time.Time, window time.Duration, failed bool) {
+	width := bucketWidth(window)
+	start := now.Truncate(width)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b := &w.buckets[(start.UnixNano()/int64(width))%errorWindowBuckets]
+	if !b.start.Equal(start) {
+		*b = windowBucket{start: start}
+	}
+	b.total++
+	if failed {
+		b.failed++
+	}
+}
+
+func (w *errorWindow) counts(now time.Time, window time.Duration) (total uint64, failed uint64) {
+	width := bucketWidth(window)
+	oldest := now.Truncate(width).Add(-width * (errorWindowBuckets - 1))
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, b := range w.buckets {
+		if !b.start.Before(oldest) {
+			total += b.total
+			failed += b.failed
+		}
+	}
+	return total, failed
+}
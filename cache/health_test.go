@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type pingingCache struct {
+	driver.Cache[int, RecordCacheItem[string]]
+	err error
+}
+
+func (p pingingCache) Ping(context.Context) error {
+	return p.err
+}
+
+type partialFetcherFunc func(context.Context) PartialResult[int, string]
+
+func (f partialFetcherFunc) FetchAllPartial(ctx context.Context) PartialResult[int, string] {
+	return f(ctx)
+}
+
+func TestRecordCache_Health(t *testing.T) {
+	failing := OnDemandFetcherFunc[int, string](func(context.Context, int) (string, error) {
+		return "", errors.New("source down")
+	})
+	loadOnce := func() AsyncFetcher[int, string] {
+		loads := 0
+		return AsyncFetcherFunc[int, string](func(context.Context) (map[int]string, error) {
+			if loads++; loads > 1 {
+				return nil, errors.New("source down")
+			}
+			return map[int]string{1: "one"}, nil
+		})
+	}
+	tests := []struct {
+		name  string
+		cache func() *RecordCache[int, string]
+		want  HealthStatus
+	}{
+		{
+			name: "healthy",
+			cache: func() *RecordCache[int, string] {
+				return NewRecordCache[int, string](pingingCache{Cache: driver.NewMemoryCache[int, RecordCacheItem[string]]()}).
+					SetAsyncFetcher(loadOnce(), time.Hour)
+			},
+			want: HealthUp,
+		},
+		{
+			name: "driver unreachable",
+			cache: func() *RecordCache[int, string] {
+				d := pingingCache{Cache: driver.NewMemoryCache[int, RecordCacheItem[string]](), err: errors.New("refused")}
+				return NewRecordCache[int, string](d).SetOnDemandFetcher(failing, time.Minute)
+			},
+			want: HealthDown,
+		},
+		{
+			name: "last refresh failed",
+			cache: func() *RecordCache[int, string] {
+				r := NewRecordCache[int, string](driver.NewMemoryCache[int, RecordCacheItem[string]]()).
+					SetAsyncFetcher(loadOnce(), time.Hour)
+				_ = r.Refresh()
+				return r
+			},
+			want: HealthDegraded,
+		},
+		{
+			name: "partial refresh applied",
+			cache: func() *RecordCache[int, string] {
+				return NewRecordCache[int, string](driver.NewMemoryCache[int, RecordCacheItem[string]]()).
+					SetPartialPolicy(PartialPolicyMerge).
+					SetPartialAsyncFetcher(partialFetcherFunc(func(context.Context) PartialResult[int, string] {
+						return PartialResult[int, string]{
+							Records:   map[int]string{1: "one"},
+							KeyErrors: map[int]error{2: errors.New("source down")},
+						}
+					}), time.Hour)
+			},
+			want: HealthDegraded,
+		},
+		{
+			name: "first load not complete",
+			cache: func() *RecordCache[int, string] {
+				return NewRecordCache[int, string](driver.NewMemoryCache[int, RecordCacheItem[string]]()).
+					SetBackgroundLoad(time.Hour).
+					SetAsyncFetcher(AsyncFetcherFunc[int, string](func(context.Context) (map[int]string, error) {
+						return nil, errors.New("source down")
+					}), time.Hour)
+			},
+			want: HealthDown,
+		},
+		{
+			name: "fetch error rate above threshold",
+			cache: func() *RecordCache[int, string] {
+				r := NewRecordCache[int, string](driver.NewMemoryCache[int, RecordCacheItem[string]]()).
+					SetHealthConfig(HealthConfig{MinFetches: 3}).
+					SetOnDemandFetcher(failing, time.Minute)
+				for k := range 3 {
+					_, _ = r.Get(context.Background(), k)
+				}
+				return r
+			},
+			want: HealthDegraded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.cache()
+			defer r.Close()
+			if got := r.Health(context.Background()); got.Status != tt.want {
+				t.Errorf("Health() = %+v, want status %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCache_HealthHandler(t *testing.T) {
+	d := pingingCache{Cache: driver.NewMemoryCache[int, RecordCacheItem[string]](), err: errors.New("refused")}
+	r := NewRecordCache[int, string](d)
+	rec := httptest.NewRecorder()
+	r.HealthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %v, want %v", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func Test_errorWindow(t *testing.T) {
+	var w errorWindow
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.observe(now, time.Minute, true)
+	w.observe(now.Add(30*time.Second), time.Minute, false)
+	if total, failed := w.counts(now.Add(30*time.Second), time.Minute); total != 2 || failed != 1 {
+		t.Errorf("counts() = %v, %v, want 2, 1", total, failed)
+	}
+	if total, failed := w.counts(now.Add(65*time.Second), time.Minute); total != 1 || failed != 0 {
+		t.Errorf("counts() after expiry = %v, %v, want 1, 0", total, failed)
+	}
+
+	var tiny errorWindow
+	tiny.observe(now, 5*time.Nanosecond, true)
+	if total, failed := tiny.counts(now, 5*time.Nanosecond); total != 1 || failed != 1 {
+		t.Errorf("counts() with a window under 10ns = %v, %v, want 1, 1", total, failed)
+	}
+}
+
+func TestRecordCache_setRefreshStatusPartial(t *testing.T) {
+	r := NewRecordCache[int, string](driver.NewMemoryCache[int, RecordCacheItem[string]]())
+	start := time.Now()
+	r.setRefreshStatus(start, &PartialFetchError[int]{Loaded: 1, KeyErrors: map[int]error{2: errors.New("down")}}, nil)
+	if last := r.Info().LastRefresh; !last.Partial || last.Error == "" {
+		t.Errorf("LastRefresh = %+v, want a partial refresh with its error", last)
+	}
+	if !r.lastSuccess.Equal(start) {
+		t.Errorf("lastSuccess = %v, want %v", r.lastSuccess, start)
+	}
+}
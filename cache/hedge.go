@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// latencySamples is the number of recent fetch latencies used to pick the hedge delay.
+	latencySamples = 256
+	// minLatencySamples is the number of latencies required before the observed percentile replaces the fallback delay.
+	minLatencySamples = 20
+)
+
+type hedging struct {
+	percentile    float64
+	fallbackDelay time.Duration
+	latencies     *latencyTracker
+}
+
+func newHedging(percentile float64, fallbackDelay time.Duration) *hedging {
+	return &hedging{
+		percentile:    percentile,
+		fallbackDelay: fallbackDelay,
+		latencies:     &latencyTracker{},
+	}
+}
+
+func (h *hedging) delay() time.Duration {
+	if d, ok := h.latencies.percentile(h.percentile); ok {
+		return d
+	}
+	return h.fallbackDelay
+}
+
+// latencyTracker keeps the most recent fetch latencies in a ring buffer.
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples [latencySamples]time.Duration
+	next    int
+	count   int
+}
+
+func (l *latencyTracker) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % latencySamples
+	l.count = min(l.count+1, latencySamples)
+}
+
+// percentile returns the p-th percentile (0-100) of the observed latencies, or false if too few have been observed.
+func (l *latencyTracker) percentile(p float64) (time.Duration, bool) {
+	l.mu.Lock()
+	if l.count < minLatencySamples {
+		l.mu.Unlock()
+		return 0, false
+	}
+	sorted := slices.Clone(l.samples[:l.count])
+	l.mu.Unlock()
+	slices.Sort(sorted)
+	i := int(p / 100 * float64(len(sorted)-1))
+	return sorted[max(0, min(i, len(sorted)-1))], true
+}
+
+type hedgeResult[V any] struct {
+	item RecordCacheItem[V]
+	err  error
+}
+
+// hedgedFetch runs fetch, and runs it again if it has not returned within the hedge delay. The first success is
+// returned and the other fetch cancelled. If the first fetch fails before the delay, its error is returned without
+// hedging.
+func (r *RecordCache[K, V]) hedgedFetch(ctx context.Context, fetch func(ctx context.Context) (RecordCacheItem[V], error)) (RecordCacheItem[V], error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan hedgeResult[V], 2)
+	launch := func() {
+		go func() {
+			item, err := fetch(ctx)
+			results <- hedgeResult[V]{item: item, err: err}
+		}()
+	}
+	launch()
+	outstanding := 1
+	timer := time.NewTimer(r.hedging.delay())
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			r.stats.hedges.Add(1)
+			outstanding++
+			launch()
+		case res := <-results:
+			outstanding--
+			if res.err == nil || outstanding == 0 {
+				return res.item, res.err
+			}
+		}
+	}
+}
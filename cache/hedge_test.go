@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLatencyTracker_percentile(t *testing.T) {
+	l := &latencyTracker{}
+	if _, ok := l.percentile(50); ok {
+		t.Errorf("percentile() ok without samples")
+	}
+	for i := 1; i <= 100; i++ {
+		l.observe(time.Duration(i) * time.Millisecond)
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{p: 0, want: time.Millisecond},
+		{p: 50, want: 50 * time.Millisecond},
+		{p: 100, want: 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got, ok := l.percentile(tt.p); !ok || got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRecordCache_SetHedging(t *testing.T) {
+	tests := []struct {
+		name       string
+		fetch      func(call int32, ctx context.Context) (int, error)
+		want       int
+		wantErr    bool
+		wantHedges uint64
+	}{
+		{
+			name: "slow first fetch is hedged",
+			fetch: func(call int32, ctx context.Context) (int, error) {
+				if call == 1 {
+					<-ctx.Done()
+					return 0, ctx.Err()
+				}
+				return 2, nil
+			},
+			want:       2,
+			wantHedges: 1,
+		},
+		{
+			name: "fast failure is not hedged",
+			fetch: func(_ int32, _ context.Context) (int, error) {
+				return 0, fmt.Errorf("error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "fast success is not hedged",
+			fetch: func(_ int32, _ context.Context) (int, error) {
+				return 1, nil
+			},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+				SetHedging(95, 20*time.Millisecond).
+				SetOnDemandFetcher(OnDemandFetcherFunc[string, int](func(ctx context.Context, _ string) (int, error) {
+					return tt.fetch(calls.Add(1), ctx)
+				}), time.Minute)
+			defer r.Close()
+			got, err := r.Get(context.Background(), "k")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+			if hedges := r.Stats().Hedges; hedges != tt.wantHedges {
+				t.Errorf("Stats().Hedges = %v, want %v", hedges, tt.wantHedges)
+			}
+		})
+	}
+}
+
+func TestRecordCache_SetHedging_observesLosers(t *testing.T) {
+	var calls atomic.Int32
+	r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+		SetHedging(95, 20*time.Millisecond).
+		SetOnDemandFetcher(OnDemandFetcherFunc[string, int](func(ctx context.Context, _ string) (int, error) {
+			if calls.Add(1) == 1 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return 2, nil
+		}), time.Minute)
+	defer r.Close()
+	if _, err := r.Get(context.Background(), "k"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	// the cancelled first fetch returns after Get
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.hedging.latencies.mu.Lock()
+		samples := slices.Clone(r.hedging.latencies.samples[:r.hedging.latencies.count])
+		r.hedging.latencies.mu.Unlock()
+		if len(samples) == 2 {
+			if slices.Max(samples) < 20*time.Millisecond {
+				t.Errorf("latencies = %v, want the cancelled fetch observed at the hedge delay or later", samples)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("latencies = %v, want both fetches observed", samples)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
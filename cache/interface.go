@@ -13,3 +13,45 @@ type AsyncFetcher[K comparable, V any] interface {
 type KeylessFetcher[V any] interface {
 	Fetch(ctx context.Context) (V, error)
 }
+
+// PartialAsyncFetcher is a variant of AsyncFetcher able to return the records it managed to load alongside per key
+// and aggregate errors. How partial results are applied is controlled by RecordCache.SetPartialPolicy.
+type PartialAsyncFetcher[K comparable, V any] interface {
+	FetchAllPartial(ctx context.Context) PartialResult[K, V]
+}
+
+// ErrorHook is called with errors which occur outside a caller's request, such as during an async refresh.
+type ErrorHook func(ctx context.Context, err error)
+
+// DeltaFetcher fetches the changes made since cursor, allowing RecordCache to apply incremental updates between full
+// loads by the AsyncFetcher. CurrentCursor returns the latest position, which is taken before every full load so that
+// changes made while it runs are applied by the next delta.
+type DeltaFetcher[K comparable, V any] interface {
+	FetchSince(ctx context.Context, cursor string) (Delta[K, V], error)
+	CurrentCursor(ctx context.Context) (string, error)
+}
+
+// RevalidatingFetcher is a variant of OnDemandFetcher for upstreams supporting conditional requests. It receives the
+// currently cached item, whose ETag and LastModified can be sent as validators, and may answer that the record has not
+// been modified. current is the zero value when the key is not cached.
+type RevalidatingFetcher[K comparable, V any] interface {
+	FetchIfModified(ctx context.Context, k K, current RecordCacheItem[V]) (Revalidation[V], error)
+}
+
+// SourcedFetcher is implemented by on demand fetchers which can report the name of the source that served a record,
+// such as a FallbackFetcher. The source is recorded on the cached RecordCacheItem.
+type SourcedFetcher[K comparable, V any] interface {
+	FetchByKeyWithSource(ctx context.Context, k K) (V, string, error)
+}
+
+// Writer writes records to the source a RecordCache is in front of, so that RecordCache.Set and RecordCache.Delete
+// keep the source and the cache in step.
+type Writer[K comparable, V any] interface {
+	Write(ctx context.Context, k K, v V) error
+	Delete(ctx context.Context, k K) error
+}
+
+// BatchWriter is optionally implemented by a Writer able to apply several writes at once, used in write-behind mode.
+type BatchWriter[K comparable, V any] interface {
+	WriteBatch(ctx context.Context, ops []WriteOp[K, V]) error
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"time"
+)
+
+// itemEnvelope is the JSON encoding of a RecordCacheItem by an item codec, holding V encoded by the value codec.
+type itemEnvelope struct {
+	V            []byte    `json:"v"`
+	T            time.Time `json:"t"`
+	Async        bool      `json:"async,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"last_modified,omitempty"`
+	Source       string    `json:"source,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+}
+
+type itemCodec[V any] struct {
+	values driver.Codec
+}
+
+// NewItemCodec returns a codec for RecordCacheItem[V] encoding V with c and the record metadata as JSON, for codecs
+// which only encode some types, such as driver.ProtoCodec.
+func NewItemCodec[V any](c driver.Codec) driver.Codec {
+	return itemCodec[V]{values: c}
+}
+
+func (ic itemCodec[V]) Marshal(v any) ([]byte, error) {
+	item, ok := v.(RecordCacheItem[V])
+	if !ok {
+		return nil, fmt.Errorf("item codec: cannot encode %T", v)
+	}
+	b, err := ic.values.Marshal(item.V)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(itemEnvelope{
+		V:            b,
+		T:            item.T,
+		Async:        item.Async,
+		ETag:         item.ETag,
+		LastModified: item.LastModified,
+		Source:       item.Source,
+		Tags:         item.Tags,
+	})
+}
+
+func (ic itemCodec[V]) Unmarshal(data []byte, v any) error {
+	item, ok := v.(*RecordCacheItem[V])
+	if !ok {
+		return fmt.Errorf("item codec: cannot decode into %T", v)
+	}
+	var e itemEnvelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	var value V
+	if err := ic.values.Unmarshal(e.V, &value); err != nil {
+		return err
+	}
+	*item = RecordCacheItem[V]{
+		V:            value,
+		T:            e.T,
+		Async:        e.Async,
+		ETag:         e.ETag,
+		LastModified: e.LastModified,
+		Source:       e.Source,
+		Tags:         e.Tags,
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+	"time"
+)
+
+func TestItemCodec(t *testing.T) {
+	c := NewItemCodec[*wrapperspb.StringValue](driver.ProtoCodec{})
+	want := RecordCacheItem[*wrapperspb.StringValue]{
+		V:      wrapperspb.String("abc"),
+		T:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Async:  true,
+		ETag:   `"v1"`,
+		Source: "primary",
+		Tags:   []string{"a", "b"},
+	}
+	b, err := c.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got RecordCacheItem[*wrapperspb.StringValue]
+	if err := c.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !proto.Equal(got.V, want.V) || !got.T.Equal(want.T) || got.Async != want.Async || got.ETag != want.ETag ||
+		got.Source != want.Source || len(got.Tags) != 2 {
+		t.Errorf("Unmarshal() got = %+v, want %+v", got, want)
+	}
+	if _, err := c.Marshal("abc"); err == nil {
+		t.Errorf("Marshal() of a string error = nil, want error")
+	}
+}
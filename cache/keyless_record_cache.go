@@ -42,6 +42,9 @@ func NewKeylessRecordCacheOnDemand[V any](driver driver.Cache[int, RecordCacheIt
 	return &KeylessRecordCache[V]{NewRecordCache[int, V](driver).SetOnDemandFetcher(newOnDemandFetcher(f), ttl)}
 }
 
+// NewKeylessRecordCacheAsync returns a cache loading its value with f every ttl. It blocks until the first load has
+// completed; if it fails, the load is retried in the background every 5 seconds until it succeeds or the cache is
+// closed, and Get returns ErrNotReady until then.
 func NewKeylessRecordCacheAsync[V any](driver driver.Cache[int, RecordCacheItem[V]], f KeylessFetcher[V], ttl time.Duration) *KeylessRecordCache[V] {
 	return &KeylessRecordCache[V]{
 		NewRecordCache[int, V](driver).
@@ -49,6 +52,7 @@ func NewKeylessRecordCacheAsync[V any](driver driver.Cache[int, RecordCacheItem[
 	}
 }
 
+// NewKeylessRecordCacheAsyncWithLogger is NewKeylessRecordCacheAsync with a logger.
 func NewKeylessRecordCacheAsyncWithLogger[V any](driver driver.Cache[int, RecordCacheItem[V]], f KeylessFetcher[V], ttl time.Duration, log *zap.Logger) *KeylessRecordCache[V] {
 	return &KeylessRecordCache[V]{
 		NewRecordCache[int, V](driver).
@@ -57,6 +61,20 @@ func NewKeylessRecordCacheAsyncWithLogger[V any](driver driver.Cache[int, Record
 	}
 }
 
+// NewKeylessRecordCacheAsyncBackground returns a cache loading its value with f every ttl, without blocking for the
+// first load, which is retried every retryInterval until it succeeds. Use Ready or WaitReady to know when the value
+// is available; until then Get returns ErrNotReady. log may be nil.
+func NewKeylessRecordCacheAsyncBackground[V any](driver driver.Cache[int, RecordCacheItem[V]], f KeylessFetcher[V], ttl time.Duration, retryInterval time.Duration, log *zap.Logger) *KeylessRecordCache[V] {
+	r := NewRecordCache[int, V](driver)
+	if log != nil {
+		r.AddLogger(log)
+	}
+	return &KeylessRecordCache[V]{
+		r.SetBackgroundLoad(retryInterval).
+			SetAsyncFetcher(newAsyncFetcher(f), ttl),
+	}
+}
+
 func (k *KeylessRecordCache[V]) Get(ctx context.Context) (V, error) {
 	return k.RecordCache.Get(ctx, 0)
 }
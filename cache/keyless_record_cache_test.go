@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ellogroup/ello-golang-cache/driver"
 	"go.uber.org/zap"
@@ -177,3 +178,26 @@ func TestNewKeylessRecordCacheAsync(t *testing.T) {
 		})
 	}
 }
+
+func TestNewKeylessRecordCacheAsyncBackground(t *testing.T) {
+	release := make(chan struct{})
+	k := NewKeylessRecordCacheAsyncBackground[string](driver.NewMemoryCache[int, RecordCacheItem[string]](),
+		KeylessFetcherFunc[string](func(context.Context) (string, error) {
+			<-release
+			return "hello", nil
+		}), time.Hour, time.Millisecond, nil)
+	defer k.Close()
+
+	if _, err := k.Get(context.Background()); !errors.Is(err, ErrNotReady) {
+		t.Errorf("Get() before the first load error = %v, want %v", err, ErrNotReady)
+	}
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := k.WaitReady(ctx); err != nil {
+		t.Fatalf("WaitReady() error = %v", err)
+	}
+	if got, err := k.Get(context.Background()); err != nil || got != "hello" {
+		t.Errorf("Get() = %v, %v, want hello", got, err)
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// FetchLimits bound the fetches made by a RecordCache, across both on demand and async fetchers. Zero values disable
+// the corresponding limit.
+type FetchLimits struct {
+	// MaxInFlight is the maximum number of concurrent fetches.
+	MaxInFlight int
+	// RatePerSecond and Burst configure a token bucket limiting the rate at which fetches start.
+	RatePerSecond float64
+	Burst         int
+	// MaxQueue is the maximum number of fetches waiting for an in flight slot, beyond which ErrFetchQueueFull is
+	// returned. Zero allows an unbounded queue.
+	MaxQueue int
+	// QueueTimeout is the maximum time to wait for an in flight slot and rate limit token, after which
+	// ErrFetchQueueTimeout is returned.
+	QueueTimeout time.Duration
+}
+
+type fetchLimiter struct {
+	slots        chan struct{}
+	waiting      atomic.Int64
+	maxQueue     int64
+	queueTimeout time.Duration
+	bucket       *tokenBucket
+}
+
+func newFetchLimiter(l FetchLimits) *fetchLimiter {
+	f := &fetchLimiter{
+		maxQueue:     int64(l.MaxQueue),
+		queueTimeout: l.QueueTimeout,
+	}
+	if l.MaxInFlight > 0 {
+		f.slots = make(chan struct{}, l.MaxInFlight)
+	}
+	if l.RatePerSecond > 0 {
+		f.bucket = newTokenBucket(l.RatePerSecond, l.Burst)
+	}
+	return f
+}
+
+// acquire waits for an in flight slot and a rate limit token, returning a func to release the slot once the fetch is
+// complete.
+func (f *fetchLimiter) acquire(parent context.Context) (func(), error) {
+	ctx := parent
+	if f.queueTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, f.queueTimeout)
+		defer cancel()
+	}
+	release := func() {}
+	if f.slots != nil {
+		if err := f.acquireSlot(ctx); err != nil {
+			return nil, waitErr(parent, err)
+		}
+		release = func() { <-f.slots }
+	}
+	if f.bucket != nil {
+		if err := f.bucket.wait(ctx); err != nil {
+			release()
+			return nil, waitErr(parent, err)
+		}
+	}
+	return release, nil
+}
+
+func (f *fetchLimiter) acquireSlot(ctx context.Context) error {
+	select {
+	case f.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if n := f.waiting.Add(1); f.maxQueue > 0 && n > f.maxQueue {
+		f.waiting.Add(-1)
+		return ErrFetchQueueFull
+	}
+	defer f.waiting.Add(-1)
+	select {
+	case f.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitErr converts the expiry of the queue timeout into ErrFetchQueueTimeout, leaving the cancellation of parent
+// untouched.
+func waitErr(parent context.Context, err error) error {
+	if err == context.DeadlineExceeded && parent.Err() == nil {
+		return ErrFetchQueueTimeout
+	}
+	return err
+}
+
+// acquireFetch waits for the fetch limits of the cache, if any have been set.
+func (r *RecordCache[K, V]) acquireFetch(ctx context.Context) (func(), error) {
+	if r.limiter == nil {
+		return func() {}, nil
+	}
+	return r.limiter.acquire(ctx)
+}
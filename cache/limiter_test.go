@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"testing"
+	"time"
+)
+
+func TestFetchLimiter_acquire(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  FetchLimits
+		held    int
+		wantErr error
+	}{
+		{
+			name:   "slot available",
+			limits: FetchLimits{MaxInFlight: 1},
+		},
+		{
+			name:    "queue timeout when slots held",
+			limits:  FetchLimits{MaxInFlight: 1, QueueTimeout: time.Millisecond},
+			held:    1,
+			wantErr: ErrFetchQueueTimeout,
+		},
+		{
+			name:    "rate limit exceeded within queue timeout",
+			limits:  FetchLimits{RatePerSecond: 1, Burst: 1, QueueTimeout: time.Millisecond},
+			held:    1,
+			wantErr: ErrFetchQueueTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newFetchLimiter(tt.limits)
+			for i := 0; i < tt.held; i++ {
+				if _, err := l.acquire(context.Background()); err != nil {
+					t.Fatalf("acquire() held error = %v", err)
+				}
+			}
+			release, err := l.acquire(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("acquire() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				release()
+			}
+		})
+	}
+}
+
+func TestFetchLimiter_queueFull(t *testing.T) {
+	l := newFetchLimiter(FetchLimits{MaxInFlight: 1, MaxQueue: 1})
+	if _, err := l.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan error)
+	go func() {
+		_, err := l.acquire(ctx)
+		queued <- err
+	}()
+	for l.waiting.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := l.acquire(context.Background()); !errors.Is(err, ErrFetchQueueFull) {
+		t.Errorf("acquire() error = %v, want %v", err, ErrFetchQueueFull)
+	}
+	cancel()
+	if err := <-queued; !errors.Is(err, context.Canceled) {
+		t.Errorf("queued acquire() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRecordCache_SetFetchLimits(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+		SetFetchLimits(FetchLimits{MaxInFlight: 1, QueueTimeout: 10 * time.Millisecond}).
+		SetOnDemandFetcher(OnDemandFetcherFunc[string, int](func(_ context.Context, _ string) (int, error) {
+			close(started)
+			<-unblock
+			return 1, nil
+		}), time.Minute)
+	defer r.Close()
+
+	go func() { _, _ = r.Get(context.Background(), "a") }()
+	<-started
+	if _, err := r.Get(context.Background(), "b"); !errors.Is(err, ErrFetchQueueTimeout) {
+		t.Errorf("Get() error = %v, want %v", err, ErrFetchQueueTimeout)
+	}
+	close(unblock)
+}
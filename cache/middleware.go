@@ -0,0 +1,267 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// FetchKind is the type of fetcher a Middleware is wrapping.
+type FetchKind string
+
+const (
+	FetchKindOnDemand FetchKind = "on_demand"
+	FetchKindAsync    FetchKind = "async"
+	FetchKindKeyless  FetchKind = "keyless"
+	FetchKindDelta    FetchKind = "delta"
+)
+
+// FetchInfo describes the fetch a Middleware is wrapping. Key is the requested key for on demand fetches, nil
+// otherwise.
+type FetchInfo struct {
+	Kind FetchKind
+	Key  any
+}
+
+// FetchCall performs a fetch. The result is captured by the wrapped fetcher, so middleware only sees the error.
+type FetchCall func(ctx context.Context, info FetchInfo) error
+
+// Middleware decorates a FetchCall, e.g. to add timeouts, retries or logging. As it does not depend on the key or
+// value types, the same Middleware can wrap any fetcher with WrapOnDemandFetcher, WrapRevalidatingFetcher,
+// WrapAsyncFetcher, WrapDeltaFetcher or WrapKeylessFetcher.
+type Middleware func(next FetchCall) FetchCall
+
+// chain applies mw to call, the first middleware being the outermost.
+func chain(call FetchCall, mw []Middleware) FetchCall {
+	for i := len(mw) - 1; i >= 0; i-- {
+		call = mw[i](call)
+	}
+	return call
+}
+
+type onDemandMiddleware[K comparable, V any] struct {
+	f  OnDemandFetcher[K, V]
+	mw []Middleware
+}
+
+// WrapOnDemandFetcher wraps f with mw. The wrapped fetcher still reports sources when f is a SourcedFetcher. Use
+// WrapRevalidatingFetcher to wrap a RevalidatingFetcher.
+func WrapOnDemandFetcher[K comparable, V any](f OnDemandFetcher[K, V], mw ...Middleware) OnDemandFetcher[K, V] {
+	return onDemandMiddleware[K, V]{f: f, mw: mw}
+}
+
+func (o onDemandMiddleware[K, V]) FetchByKey(ctx context.Context, k K) (V, error) {
+	v, _, err := o.FetchByKeyWithSource(ctx, k)
+	return v, err
+}
+
+func (o onDemandMiddleware[K, V]) FetchByKeyWithSource(ctx context.Context, k K) (V, string, error) {
+	var v V
+	var source string
+	err := chain(func(ctx context.Context, _ FetchInfo) error {
+		var err error
+		if sf, ok := o.f.(SourcedFetcher[K, V]); ok {
+			v, source, err = sf.FetchByKeyWithSource(ctx, k)
+		} else {
+			v, err = o.f.FetchByKey(ctx, k)
+		}
+		return err
+	}, o.mw)(ctx, FetchInfo{Kind: FetchKindOnDemand, Key: k})
+	return v, source, err
+}
+
+type revalidatingMiddleware[K comparable, V any] struct {
+	f  RevalidatingFetcher[K, V]
+	mw []Middleware
+}
+
+// WrapRevalidatingFetcher wraps f with mw, so that the wrapped fetcher can still be set with SetRevalidatingFetcher.
+func WrapRevalidatingFetcher[K comparable, V any](f RevalidatingFetcher[K, V], mw ...Middleware) RevalidatingFetcher[K, V] {
+	return revalidatingMiddleware[K, V]{f: f, mw: mw}
+}
+
+func (r revalidatingMiddleware[K, V]) FetchIfModified(ctx context.Context, k K, current RecordCacheItem[V]) (Revalidation[V], error) {
+	var rv Revalidation[V]
+	err := chain(func(ctx context.Context, _ FetchInfo) error {
+		var err error
+		rv, err = r.f.FetchIfModified(ctx, k, current)
+		return err
+	}, r.mw)(ctx, FetchInfo{Kind: FetchKindOnDemand, Key: k})
+	return rv, err
+}
+
+type asyncMiddleware[K comparable, V any] struct {
+	f  AsyncFetcher[K, V]
+	mw []Middleware
+}
+
+// WrapAsyncFetcher wraps f with mw. The wrapped fetcher still returns partial results when f is a
+// PartialAsyncFetcher; the middleware sees only their Err, not the errors of individual keys.
+func WrapAsyncFetcher[K comparable, V any](f AsyncFetcher[K, V], mw ...Middleware) AsyncFetcher[K, V] {
+	return asyncMiddleware[K, V]{f: f, mw: mw}
+}
+
+func (a asyncMiddleware[K, V]) FetchAll(ctx context.Context) (map[K]V, error) {
+	res := a.FetchAllPartial(ctx)
+	return res.Records, res.err()
+}
+
+func (a asyncMiddleware[K, V]) FetchAllPartial(ctx context.Context) PartialResult[K, V] {
+	var res PartialResult[K, V]
+	err := chain(func(ctx context.Context, _ FetchInfo) error {
+		if pf, ok := a.f.(PartialAsyncFetcher[K, V]); ok {
+			res = pf.FetchAllPartial(ctx)
+		} else {
+			m, err := a.f.FetchAll(ctx)
+			res = PartialResult[K, V]{Records: m, Err: err}
+		}
+		// keys which failed to load do not fail the fetch, so that middleware such as WithRetry does not load the whole
+		// dataset again for them
+		return res.Err
+	}, a.mw)(ctx, FetchInfo{Kind: FetchKindAsync})
+	if err != nil && res.Err == nil {
+		// the error came from a middleware rather than the fetcher
+		res.Err = err
+	}
+	return res
+}
+
+type deltaMiddleware[K comparable, V any] struct {
+	f  DeltaFetcher[K, V]
+	mw []Middleware
+}
+
+// WrapDeltaFetcher wraps f with mw. Both FetchSince and CurrentCursor go through mw.
+func WrapDeltaFetcher[K comparable, V any](f DeltaFetcher[K, V], mw ...Middleware) DeltaFetcher[K, V] {
+	return deltaMiddleware[K, V]{f: f, mw: mw}
+}
+
+func (d deltaMiddleware[K, V]) FetchSince(ctx context.Context, cursor string) (Delta[K, V], error) {
+	var delta Delta[K, V]
+	err := chain(func(ctx context.Context, _ FetchInfo) error {
+		var err error
+		delta, err = d.f.FetchSince(ctx, cursor)
+		return err
+	}, d.mw)(ctx, FetchInfo{Kind: FetchKindDelta})
+	return delta, err
+}
+
+func (d deltaMiddleware[K, V]) CurrentCursor(ctx context.Context) (string, error) {
+	var cursor string
+	err := chain(func(ctx context.Context, _ FetchInfo) error {
+		var err error
+		cursor, err = d.f.CurrentCursor(ctx)
+		return err
+	}, d.mw)(ctx, FetchInfo{Kind: FetchKindDelta})
+	return cursor, err
+}
+
+type keylessMiddleware[V any] struct {
+	f  KeylessFetcher[V]
+	mw []Middleware
+}
+
+// WrapKeylessFetcher wraps f with mw.
+func WrapKeylessFetcher[V any](f KeylessFetcher[V], mw ...Middleware) KeylessFetcher[V] {
+	return keylessMiddleware[V]{f: f, mw: mw}
+}
+
+func (k keylessMiddleware[V]) Fetch(ctx context.Context) (V, error) {
+	var v V
+	err := chain(func(ctx context.Context, _ FetchInfo) error {
+		var err error
+		v, err = k.f.Fetch(ctx)
+		return err
+	}, k.mw)(ctx, FetchInfo{Kind: FetchKindKeyless})
+	return v, err
+}
+
+// WithTimeout cancels fetches which take longer than d. The fetcher must honour context cancellation.
+func WithTimeout(d time.Duration) Middleware {
+	return func(next FetchCall) FetchCall {
+		return func(ctx context.Context, info FetchInfo) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, info)
+		}
+	}
+}
+
+// WithRetry retries failed fetches up to attempts times in total, doubling the wait between attempts starting from
+// backoff. ErrNotFound is not retried.
+func WithRetry(attempts int, backoff time.Duration) Middleware {
+	return func(next FetchCall) FetchCall {
+		return func(ctx context.Context, info FetchInfo) error {
+			var err error
+			wait := backoff
+			for i := 0; i < attempts || i == 0; i++ {
+				if i > 0 {
+					t := time.NewTimer(wait)
+					select {
+					case <-ctx.Done():
+						t.Stop()
+						return errors.Join(err, ctx.Err())
+					case <-t.C:
+					}
+					wait *= 2
+				}
+				if err = next(ctx, info); err == nil || errors.Is(err, ErrNotFound) {
+					return err
+				}
+			}
+			return err
+		}
+	}
+}
+
+// WithRateLimit limits fetches to perSecond, allowing bursts of up to burst fetches. The limit is shared by every
+// fetcher wrapped with the returned Middleware.
+func WithRateLimit(perSecond float64, burst int) Middleware {
+	b := newTokenBucket(perSecond, burst)
+	return func(next FetchCall) FetchCall {
+		return func(ctx context.Context, info FetchInfo) error {
+			if err := b.wait(ctx); err != nil {
+				return err
+			}
+			return next(ctx, info)
+		}
+	}
+}
+
+// WithLogging logs every fetch and its duration at debug level, and failed fetches at warn level.
+func WithLogging(log *zap.Logger) Middleware {
+	return func(next FetchCall) FetchCall {
+		return func(ctx context.Context, info FetchInfo) error {
+			start := time.Now()
+			err := next(ctx, info)
+			fields := []zap.Field{
+				zap.String("Kind", string(info.Kind)),
+				zap.Any("Key", info.Key),
+				zap.Duration("Duration", time.Since(start)),
+			}
+			if err != nil {
+				log.Warn("Fetch failed", append(fields, zap.Error(err))...)
+				return err
+			}
+			log.Debug("Fetched", fields...)
+			return nil
+		}
+	}
+}
+
+// StartSpanFunc starts a span for a fetch, returning the context to fetch with and a func to end the span with the
+// fetch error. It allows any tracing library to be plugged in with WithTracing.
+type StartSpanFunc func(ctx context.Context, info FetchInfo) (context.Context, func(err error))
+
+// WithTracing wraps every fetch in a span started by start.
+func WithTracing(start StartSpanFunc) Middleware {
+	return func(next FetchCall) FetchCall {
+		return func(ctx context.Context, info FetchInfo) error {
+			ctx, end := start(ctx, info)
+			err := next(ctx, info)
+			end(err)
+			return err
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWrapOnDemandFetcher(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(next FetchCall) FetchCall {
+			return func(ctx context.Context, info FetchInfo) error {
+				order = append(order, name)
+				if info.Kind != FetchKindOnDemand || info.Key != "k" {
+					t.Errorf("FetchInfo = %+v", info)
+				}
+				return next(ctx, info)
+			}
+		}
+	}
+	f := WrapOnDemandFetcher[string, int](OnDemandFetcherFunc[string, int](func(_ context.Context, k string) (int, error) {
+		order = append(order, "fetch")
+		return len(k), nil
+	}), record("outer"), record("inner"))
+
+	got, err := f.FetchByKey(context.Background(), "k")
+	if err != nil || got != 1 {
+		t.Fatalf("FetchByKey() = %v, %v, want 1, nil", got, err)
+	}
+	if want := fmt.Sprint([]string{"outer", "inner", "fetch"}); fmt.Sprint(order) != want {
+		t.Errorf("call order = %v, want %v", order, want)
+	}
+}
+
+func TestWrapOnDemandFetcher_source(t *testing.T) {
+	f := WrapOnDemandFetcher[string, int](NewFallbackFetcher(
+		NamedFetcher[string, int]{Name: "primary", Fetcher: namedFetcherMock{v: 1}},
+	))
+	_, source, err := f.(SourcedFetcher[string, int]).FetchByKeyWithSource(context.Background(), "k")
+	if err != nil || source != "primary" {
+		t.Errorf("FetchByKeyWithSource() source = %v, err = %v, want primary", source, err)
+	}
+}
+
+func TestWrapRevalidatingFetcher(t *testing.T) {
+	var infos []FetchInfo
+	m := &revalidatingFetcherMock{}
+	f := WrapRevalidatingFetcher[string, int](m, func(next FetchCall) FetchCall {
+		return func(ctx context.Context, info FetchInfo) error {
+			infos = append(infos, info)
+			return next(ctx, info)
+		}
+	})
+	rv, err := f.FetchIfModified(context.Background(), "k", RecordCacheItem[int]{ETag: "v1"})
+	if err != nil || !rv.NotModified || m.got.ETag != "v1" {
+		t.Errorf("FetchIfModified() = %+v, %v, sent %+v, want not modified with validators", rv, err, m.got)
+	}
+	if len(infos) != 1 || infos[0] != (FetchInfo{Kind: FetchKindOnDemand, Key: "k"}) {
+		t.Errorf("middleware saw %+v", infos)
+	}
+}
+
+func TestWrapDeltaFetcher(t *testing.T) {
+	var infos []FetchInfo
+	f := WrapDeltaFetcher[string, int](&deltaFetcherMock{}, func(next FetchCall) FetchCall {
+		return func(ctx context.Context, info FetchInfo) error {
+			infos = append(infos, info)
+			return next(ctx, info)
+		}
+	})
+	if cursor, err := f.CurrentCursor(context.Background()); err != nil || cursor != "1" {
+		t.Errorf("CurrentCursor() = %v, %v, want 1", cursor, err)
+	}
+	if d, err := f.FetchSince(context.Background(), "1"); err != nil || d.Cursor != "2" {
+		t.Errorf("FetchSince() = %+v, %v, want cursor 2", d, err)
+	}
+	if len(infos) != 2 || infos[0].Kind != FetchKindDelta || infos[1].Kind != FetchKindDelta {
+		t.Errorf("middleware saw %+v, want 2 delta fetches", infos)
+	}
+}
+
+func TestWrapAsyncFetcher_partial(t *testing.T) {
+	f := WrapAsyncFetcher[string, int](newPartialAsyncFetcher[string, int](partialAsyncFetcherMock{}))
+	res := f.(PartialAsyncFetcher[string, int]).FetchAllPartial(context.Background())
+	if len(res.Records) != 1 || len(res.KeyErrors) != 1 {
+		t.Errorf("FetchAllPartial() = %+v, want 1 record and 1 key error", res)
+	}
+}
+
+func TestWrapAsyncFetcher_partialRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "key errors are not retried", wantCalls: 1},
+		{name: "fetch error is retried", err: errors.New("source down"), wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			var logged []error
+			logging := func(next FetchCall) FetchCall {
+				return func(ctx context.Context, info FetchInfo) error {
+					err := next(ctx, info)
+					if err != nil {
+						logged = append(logged, err)
+					}
+					return err
+				}
+			}
+			f := WrapAsyncFetcher[int, string](newPartialAsyncFetcher[int, string](partialFetcherFunc(func(context.Context) PartialResult[int, string] {
+				calls++
+				return PartialResult[int, string]{
+					Records:   map[int]string{1: "one"},
+					KeyErrors: map[int]error{2: errors.New("source down")},
+					Err:       tt.err,
+				}
+			})), WithRetry(3, time.Millisecond), logging)
+			res := f.(PartialAsyncFetcher[int, string]).FetchAllPartial(context.Background())
+			if calls != tt.wantCalls {
+				t.Errorf("fetcher called %v times, want %v", calls, tt.wantCalls)
+			}
+			if len(res.Records) != 1 || len(res.KeyErrors) != 1 || !errors.Is(res.Err, tt.err) {
+				t.Errorf("FetchAllPartial() = %+v, want 1 record, 1 key error and err %v", res, tt.err)
+			}
+			if tt.err == nil && len(logged) != 0 {
+				t.Errorf("middleware saw errors %v, want none", logged)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempts  int
+		failures  int
+		err       error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "succeeds after retrying",
+			attempts:  3,
+			failures:  2,
+			err:       fmt.Errorf("error"),
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after attempts",
+			attempts:  2,
+			failures:  5,
+			err:       fmt.Errorf("error"),
+			wantCalls: 2,
+			wantErr:   true,
+		},
+		{
+			name:      "not found is not retried",
+			attempts:  3,
+			failures:  5,
+			err:       ErrNotFound,
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			f := WrapKeylessFetcher[string](KeylessFetcherFunc[string](func(_ context.Context) (string, error) {
+				calls++
+				if calls <= tt.failures {
+					return "", tt.err
+				}
+				return "hello", nil
+			}), WithRetry(tt.attempts, time.Millisecond))
+			_, err := f.Fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	f := WrapAsyncFetcher[string, int](AsyncFetcherFunc[string, int](func(ctx context.Context) (map[string]int, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), WithTimeout(time.Millisecond))
+	if _, err := f.FetchAll(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchAll() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	f := WrapKeylessFetcher[string](&mockFetcher{}, WithRateLimit(1, 1))
+	if _, err := f.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() within burst error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.Fetch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Fetch() over limit error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWithTracing(t *testing.T) {
+	var ended error
+	f := WrapKeylessFetcher[string](&mockFetcherError{}, WithTracing(func(ctx context.Context, _ FetchInfo) (context.Context, func(error)) {
+		return ctx, func(err error) { ended = err }
+	}))
+	if _, err := f.Fetch(context.Background()); err == nil || ended != err {
+		t.Errorf("span ended with %v, want %v", ended, err)
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+)
+
+// PartialPolicy determines how a partial async result is applied to the existing dataset.
+type PartialPolicy int
+
+const (
+	// PartialPolicyDiscard ignores partial results and keeps the existing dataset until the next full load.
+	PartialPolicyDiscard PartialPolicy = iota
+	// PartialPolicyMerge updates the records which were loaded and keeps existing records for those which were not.
+	PartialPolicyMerge
+	// PartialPolicyReplace replaces the existing dataset with the records which were loaded.
+	PartialPolicyReplace
+)
+
+// PartialResult is returned by a PartialAsyncFetcher. Records holds everything that was loaded, KeyErrors the errors
+// for individual keys which could not be loaded and Err any error which is not specific to a key.
+type PartialResult[K comparable, V any] struct {
+	Records   map[K]V
+	KeyErrors map[K]error
+	Err       error
+}
+
+// err returns a *PartialFetchError when some records were loaded or some keys failed alongside an error, Err when the
+// fetch failed outright, and nil otherwise.
+func (p PartialResult[K, V]) err() error {
+	if p.Err == nil && len(p.KeyErrors) == 0 {
+		return nil
+	}
+	if len(p.Records) == 0 && len(p.KeyErrors) == 0 {
+		return p.Err
+	}
+	return &PartialFetchError[K]{Loaded: len(p.Records), KeyErrors: p.KeyErrors, Err: p.Err}
+}
+
+// PartialFetchError describes an async fetch which did not load all records.
+type PartialFetchError[K comparable] struct {
+	Loaded    int
+	KeyErrors map[K]error
+	Err       error
+}
+
+func (e *PartialFetchError[K]) Error() string {
+	msg := fmt.Sprintf("partial fetch: loaded %d records, %d keys failed", e.Loaded, len(e.KeyErrors))
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *PartialFetchError[K]) Unwrap() error {
+	return e.Err
+}
+
+type partialAsyncFetcher[K comparable, V any] struct {
+	f PartialAsyncFetcher[K, V]
+}
+
+func (p partialAsyncFetcher[K, V]) FetchAll(ctx context.Context) (map[K]V, error) {
+	res := p.f.FetchAllPartial(ctx)
+	return res.Records, res.err()
+}
+
+func (p partialAsyncFetcher[K, V]) FetchAllPartial(ctx context.Context) PartialResult[K, V] {
+	return p.f.FetchAllPartial(ctx)
+}
+
+func newPartialAsyncFetcher[K comparable, V any](f PartialAsyncFetcher[K, V]) partialAsyncFetcher[K, V] {
+	return partialAsyncFetcher[K, V]{f: f}
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+type partialAsyncFetcherMock struct{}
+
+func (p partialAsyncFetcherMock) FetchAllPartial(_ context.Context) PartialResult[string, int] {
+	return PartialResult[string, int]{
+		Records:   map[string]int{"active1": 100},
+		KeyErrors: map[string]error{"active2": fmt.Errorf("error")},
+	}
+}
+
+func TestRecordCache_refreshAllRecordsPartial(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    PartialPolicy
+		wantErr   bool
+		wantHas   map[string]bool
+		wantValue int
+	}{
+		{
+			name:      "discard keeps existing dataset",
+			policy:    PartialPolicyDiscard,
+			wantErr:   true,
+			wantHas:   map[string]bool{"active1": true, "active2": true},
+			wantValue: 1,
+		},
+		{
+			name:      "merge updates loaded records and keeps the rest",
+			policy:    PartialPolicyMerge,
+			wantHas:   map[string]bool{"active1": true, "active2": true},
+			wantValue: 100,
+		},
+		{
+			name:      "replace keeps only loaded records",
+			policy:    PartialPolicyReplace,
+			wantHas:   map[string]bool{"active1": true, "active2": false},
+			wantValue: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+			c.Set(context.Background(), "active1", RecordCacheItem[int]{V: 1, T: time.Now(), Async: true})
+			c.Set(context.Background(), "active2", RecordCacheItem[int]{V: 2, T: time.Now(), Async: true})
+			var hooked error
+			r := &RecordCache[string, int]{
+				log:             zap.NewNop(),
+				cache:           c,
+				onDemandFetcher: newOnDemandFetcherMock(),
+				asyncFetcher:    newPartialAsyncFetcher[string, int](partialAsyncFetcherMock{}),
+				partialPolicy:   tt.policy,
+				errorHooks:      []ErrorHook{func(_ context.Context, err error) { hooked = err }},
+			}
+			partial, err := r.refreshAllRecords()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("refreshAllRecords() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && partial == nil {
+				t.Errorf("refreshAllRecords() partial = nil, want the partial fetch error")
+			}
+			var partialErr *PartialFetchError[string]
+			if !errors.As(hooked, &partialErr) || len(partialErr.KeyErrors) != 1 {
+				t.Errorf("error hook got = %v, want PartialFetchError with 1 key error", hooked)
+			}
+			for k, want := range tt.wantHas {
+				if got := c.Has(context.Background(), k); got != want {
+					t.Errorf("Has(%v) = %v, want %v", k, got, want)
+				}
+			}
+			if v, _ := c.Get(context.Background(), "active1"); v.V != tt.wantValue {
+				t.Errorf("Get() got = %v, want %v", v.V, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestPartialResult_err(t *testing.T) {
+	fetchErr := fmt.Errorf("error")
+	tests := []struct {
+		name        string
+		res         PartialResult[string, int]
+		wantErr     error
+		wantPartial bool
+	}{
+		{
+			name: "complete",
+			res:  PartialResult[string, int]{Records: map[string]int{"a": 1}},
+		},
+		{
+			name:    "failed outright",
+			res:     PartialResult[string, int]{Err: fetchErr},
+			wantErr: fetchErr,
+		},
+		{
+			name:        "records loaded before failing",
+			res:         PartialResult[string, int]{Records: map[string]int{"a": 1}, Err: fetchErr},
+			wantErr:     fetchErr,
+			wantPartial: true,
+		},
+		{
+			name:        "keys failed",
+			res:         PartialResult[string, int]{KeyErrors: map[string]error{"a": fetchErr}},
+			wantPartial: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.res.err()
+			var partialErr *PartialFetchError[string]
+			if errors.As(err, &partialErr) != tt.wantPartial {
+				t.Errorf("err() = %v, want partial %v", err, tt.wantPartial)
+			}
+			if tt.wantPartial && partialErr.Err != tt.wantErr || !tt.wantPartial && err != tt.wantErr {
+				t.Errorf("err() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
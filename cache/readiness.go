@@ -0,0 +1,41 @@
+package cache
+
+import "sync"
+
+// readiness tracks whether a cache has completed its first successful load. The zero value is ready to use and is not
+// ready until markReady is called.
+type readiness struct {
+	mu    sync.Mutex
+	ch    chan struct{}
+	ready bool
+}
+
+func (r *readiness) channel() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ch == nil {
+		r.ch = make(chan struct{})
+		if r.ready {
+			close(r.ch)
+		}
+	}
+	return r.ch
+}
+
+func (r *readiness) markReady() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ready {
+		return
+	}
+	r.ready = true
+	if r.ch != nil {
+		close(r.ch)
+	}
+}
+
+func (r *readiness) isReady() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ready
+}
@@ -2,10 +2,13 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ellogroup/ello-golang-cache/driver"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	"net/http"
+	"sync"
 	"time"
 )
 
@@ -13,6 +16,9 @@ import (
 // gap in order to provide a grace period for checking stale records.
 const asyncCacheCheckFrequency = 1 * time.Minute
 
+// defaultStartupRetryInterval is how long to wait between attempts of the first async load when it fails.
+const defaultStartupRetryInterval = 5 * time.Second
+
 // RecordCache for a detailed explanation of the below, RecordCacheItem, KeylessRecordCache and driver.Cache
 // please see https://ellogroup.atlassian.net/wiki/spaces/EP/pages/12648450/Cache+Package
 type RecordCache[K comparable, V any] struct {
@@ -20,16 +26,61 @@ type RecordCache[K comparable, V any] struct {
 	onDemandFetcher OnDemandFetcher[K, V]
 	asyncFetcher    AsyncFetcher[K, V]
 	cache           driver.Cache[K, RecordCacheItem[V]]
+	cacheV2         driver.CacheV2[K, RecordCacheItem[V]]
 	recordTtl       time.Duration
 	allTtl          time.Duration
 	lastUpdated     time.Time
 	cron            *cron.Cron
+	backgroundLoad  bool
+	retryInterval   time.Duration
+	readiness       readiness
+	refreshMu       sync.Mutex
+	done            chan struct{}
+	closeOnce       sync.Once
+	partialPolicy   PartialPolicy
+	errorHooks      []ErrorHook
+	guard           *RefreshGuard[K, V]
+	asyncCount      int
+	rejections      int
+	retryAt         time.Time
+	lastRejection   error
+	deltaFetcher    DeltaFetcher[K, V]
+	cursor          string
+	staleRetention  time.Duration
+	stats           stats
+	limiter         *fetchLimiter
+	hedging         *hedging
+	fetchTimeout    time.Duration
+	flights         flightGroup[K, V]
+	writer          Writer[K, V]
+	writeBehind     *writeBehind[K, V]
+	tagger          func(k K, v V) []string
+	tags            driver.TagIndex[K]
+	name            string
+	statusMu        sync.Mutex
+	lastRefresh     RefreshStatus
+	lastSuccess     time.Time
+	healthConfig    HealthConfig
+	fetchWindow     errorWindow
 }
 
 func NewRecordCache[K comparable, V any](cache driver.Cache[K, RecordCacheItem[V]]) *RecordCache[K, V] {
 	return &RecordCache[K, V]{
-		cache: cache,
-		log:   zap.NewNop(),
+		cache:         cache,
+		log:           zap.NewNop(),
+		retryInterval: defaultStartupRetryInterval,
+		done:          make(chan struct{}),
+	}
+}
+
+// NewRecordCacheV2 returns a RecordCache using a driver which reports failures, so that Get can tell a miss from the
+// driver being unavailable and return ErrDriverUnavailable instead of fetching.
+func NewRecordCacheV2[K comparable, V any](cache driver.CacheV2[K, RecordCacheItem[V]]) *RecordCache[K, V] {
+	return &RecordCache[K, V]{
+		cacheV2:       cache,
+		log:           zap.NewNop(),
+		retryInterval: defaultStartupRetryInterval,
+		done:          make(chan struct{}),
 	}
 }
 
@@ -38,16 +89,112 @@ func (r *RecordCache[K, V]) AddLogger(l *zap.Logger) *RecordCache[K, V] {
 	return r
 }
 
+// SetBackgroundLoad makes the first load of the AsyncFetcher run in the background instead of blocking
+// SetAsyncFetcher. The load is retried every retryInterval until it succeeds; use Ready or WaitReady to know when the
+// data is available. Must be called before SetAsyncFetcher.
+func (r *RecordCache[K, V]) SetBackgroundLoad(retryInterval time.Duration) *RecordCache[K, V] {
+	r.backgroundLoad = true
+	if retryInterval > 0 {
+		r.retryInterval = retryInterval
+	}
+	return r
+}
+
+// AddErrorHook registers a hook called with errors which occur in the background, such as failed or partial async
+// refreshes.
+func (r *RecordCache[K, V]) AddErrorHook(h ErrorHook) *RecordCache[K, V] {
+	r.errorHooks = append(r.errorHooks, h)
+	return r
+}
+
+// SetPartialPolicy sets how results of an async fetch which returned an error alongside some records are applied.
+// Defaults to PartialPolicyDiscard.
+func (r *RecordCache[K, V]) SetPartialPolicy(p PartialPolicy) *RecordCache[K, V] {
+	r.partialPolicy = p
+	return r
+}
+
+// SetRefreshGuard validates every async load which would replace the dataset. Rejected loads keep the existing data
+// and are reported to the error hooks.
+func (r *RecordCache[K, V]) SetRefreshGuard(g RefreshGuard[K, V]) *RecordCache[K, V] {
+	r.guard = &g
+	return r
+}
+
+// SetFetchLimits bounds the concurrency and rate of fetches made by the cache. Fetches which cannot be queued fail
+// with ErrFetchQueueFull, and those waiting longer than the queue timeout with ErrFetchQueueTimeout.
+func (r *RecordCache[K, V]) SetFetchLimits(l FetchLimits) *RecordCache[K, V] {
+	r.limiter = newFetchLimiter(l)
+	return r
+}
+
+// SetHedging issues a second on demand fetch when the first has not returned within the given percentile (0-100) of
+// recently observed fetch latencies, including failed and cancelled fetches, using whichever succeeds first and
+// cancelling the other. fallbackDelay is used until enough latencies have been observed.
+func (r *RecordCache[K, V]) SetHedging(percentile float64, fallbackDelay time.Duration) *RecordCache[K, V] {
+	r.hedging = newHedging(percentile, fallbackDelay)
+	return r
+}
+
 func (r *RecordCache[K, V]) SetOnDemandFetcher(f OnDemandFetcher[K, V], ttl time.Duration) *RecordCache[K, V] {
 	r.onDemandFetcher = f
 	return r.refreshStaleRecordsEvery(ttl)
 }
 
+// SetAsyncFetcher loads every record with f every ttl. Unless SetBackgroundLoad was called, it blocks until the first
+// load has completed; if it fails, the load is retried in the background every 5 seconds (or the interval given to
+// SetBackgroundLoad) until it succeeds or the cache is closed, and Get returns ErrNotReady until then.
 func (r *RecordCache[K, V]) SetAsyncFetcher(f AsyncFetcher[K, V], ttl time.Duration) *RecordCache[K, V] {
 	r.asyncFetcher = f
 	return r.refreshAllRecordsEvery(ttl)
 }
 
+// SetHybridFetchers sets both fetchers. All records are preloaded by the AsyncFetcher every asyncTtl, and keys that
+// have appeared since the last full load are fetched by the OnDemandFetcher. Records loaded by the AsyncFetcher are
+// considered stale after asyncTtl (plus the check grace period), while records loaded on demand are stale after
+// recordTtl. A full load replaces all async records but leaves records fetched on demand until they go stale.
+func (r *RecordCache[K, V]) SetHybridFetchers(a AsyncFetcher[K, V], asyncTtl time.Duration, o OnDemandFetcher[K, V], recordTtl time.Duration) *RecordCache[K, V] {
+	return r.SetOnDemandFetcher(o, recordTtl).SetAsyncFetcher(a, asyncTtl)
+}
+
+// SetPartialAsyncFetcher sets an async fetcher able to return partial results, which are applied according to the
+// partial policy.
+func (r *RecordCache[K, V]) SetPartialAsyncFetcher(f PartialAsyncFetcher[K, V], ttl time.Duration) *RecordCache[K, V] {
+	return r.SetAsyncFetcher(newPartialAsyncFetcher(f), ttl)
+}
+
+// SetDeltaFetcher applies incremental changes every interval between the full loads of the AsyncFetcher. The cursor
+// is persisted in the driver when it implements driver.MetaStore.
+func (r *RecordCache[K, V]) SetDeltaFetcher(f DeltaFetcher[K, V], every time.Duration) *RecordCache[K, V] {
+	r.deltaFetcher = f
+	if err := r.setSchedule(); err != nil {
+		r.log.Error("Could not start scheduler", zap.Error(err))
+		return r
+	}
+	if _, err := r.cron.AddFunc("@every "+every.String(), func() { _ = r.refreshDelta() }); err != nil {
+		r.log.Error("Could not schedule delta refresh", zap.Error(err))
+	}
+	r.log.Debug("Delta refresh set", zap.String("Every", every.String()))
+	return r
+}
+
+// SetRevalidatingFetcher sets an on demand fetcher able to answer conditional requests. Stale records are kept for
+// retention after going stale, rather than removed by the minute sweep, so that their validators can be sent when
+// they are next requested.
+func (r *RecordCache[K, V]) SetRevalidatingFetcher(f RevalidatingFetcher[K, V], ttl time.Duration, retention time.Duration) *RecordCache[K, V] {
+	r.staleRetention = retention
+	return r.SetOnDemandFetcher(newRevalidatingFetcher(f), ttl)
+}
+
+// SetFallbackFetchers sets an ordered list of on demand fetchers, each tried in turn when the previous one fails or
+// returns ErrNotFound. The name of the fetcher which served a record is stored in RecordCacheItem.Source and counted
+// in Stats.
+func (r *RecordCache[K, V]) SetFallbackFetchers(ttl time.Duration, fetchers ...NamedFetcher[K, V]) *RecordCache[K, V] {
+	f := NewFallbackFetcher(fetchers...)
+	f.cacheLog = func() *zap.Logger { return r.log }
+	return r.SetOnDemandFetcher(f, ttl)
+}
+
 func (r *RecordCache[K, V]) refreshStaleRecordsEvery(ttl time.Duration) *RecordCache[K, V] {
 	r.recordTtl = ttl
 	err := r.setSchedule()
@@ -60,6 +207,12 @@ func (r *RecordCache[K, V]) refreshStaleRecordsEvery(ttl time.Duration) *RecordC
 
 func (r *RecordCache[K, V]) refreshAllRecordsEvery(ttl time.Duration) *RecordCache[K, V] {
 	r.allTtl = ttl
+	if r.backgroundLoad {
+		go r.loadUntilReady()
+	} else if err := r.refreshAsyncIfDue(); err != nil {
+		r.log.Warn("Initial load failed, retrying in background", zap.Error(err))
+		go r.loadUntilReady()
+	}
 	err := r.setSchedule()
 	if err != nil {
 		r.log.Error("Could not start scheduler", zap.Error(err))
@@ -68,50 +221,212 @@ func (r *RecordCache[K, V]) refreshAllRecordsEvery(ttl time.Duration) *RecordCac
 	return r
 }
 
-func (r *RecordCache[K, V]) needRefreshing(k K) bool {
-	v, ok := r.cache.Get(context.Background(), k)
-	if r.onDemandFetcher == nil {
-		return !ok || v.IsStale(r.allTtl+asyncCacheCheckFrequency)
+// records returns the driver as a CacheV2, adapting a Cache driver.
+func (r *RecordCache[K, V]) records() driver.CacheV2[K, RecordCacheItem[V]] {
+	if r.cacheV2 != nil {
+		return r.cacheV2
+	}
+	return driver.FromV1(r.cache)
+}
+
+// backend returns the driver as given, to be checked for the optional driver interfaces.
+func (r *RecordCache[K, V]) backend() any {
+	if r.cacheV2 != nil {
+		return r.cacheV2
+	}
+	return r.cache
+}
+
+// isStale applies the async ttl to records loaded by the AsyncFetcher (or to every record when there is no
+// OnDemandFetcher) and the record ttl to records fetched on demand.
+func (r *RecordCache[K, V]) isStale(v RecordCacheItem[V]) bool {
+	if r.onDemandFetcher == nil || v.Async {
+		return v.IsStale(r.allTtl + asyncCacheCheckFrequency)
+	}
+	return v.IsStale(r.recordTtl)
+}
+
+// isExpired reports whether a record is stale for longer than the stale retention, and can be removed.
+func (r *RecordCache[K, V]) isExpired(v RecordCacheItem[V]) bool {
+	if r.onDemandFetcher == nil || v.Async {
+		return v.IsStale(r.allTtl + asyncCacheCheckFrequency + r.staleRetention)
+	}
+	return v.IsStale(r.recordTtl + r.staleRetention)
+}
+
+// Ready returns a channel which is closed once the first load of the AsyncFetcher has succeeded. Caches without an
+// AsyncFetcher are always ready.
+func (r *RecordCache[K, V]) Ready() <-chan struct{} {
+	if r.asyncFetcher == nil {
+		r.readiness.markReady()
 	}
-	return !ok || v.IsStale(r.recordTtl)
+	return r.readiness.channel()
+}
+
+// WaitReady blocks until the cache is ready or ctx is done, in which case the context error is returned.
+func (r *RecordCache[K, V]) WaitReady(ctx context.Context) error {
+	select {
+	case <-r.Ready():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsReady reports whether the cache is ready without blocking.
+func (r *RecordCache[K, V]) IsReady() bool {
+	return r.asyncFetcher == nil || r.readiness.isReady()
+}
+
+// ReadinessHandler returns a http.Handler responding 200 once the cache is ready and 503 until then, suitable for
+// a Kubernetes readiness probe.
+func (r *RecordCache[K, V]) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !r.IsReady() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ready"))
+	})
+}
+
+// Close stops the refresh schedule and any background load, and flushes pending writes in write-behind mode, waiting
+// at most WriteBehindConfig.CloseTimeout. The cache can still be read after closing, but will no longer be refreshed
+// asynchronously.
+func (r *RecordCache[K, V]) Close() {
+	r.closeOnce.Do(func() {
+		if r.writeBehind != nil {
+			r.writeBehind.close()
+		}
+		if r.cron != nil {
+			<-r.cron.Stop().Done()
+		}
+		if r.done != nil {
+			close(r.done)
+		}
+		if r.writeBehind != nil {
+			<-r.writeBehind.stopped
+		}
+	})
 }
 
 func (r *RecordCache[K, V]) Get(ctx context.Context, k K) (V, error) {
-	if r.needRefreshing(k) {
-		if err := r.refreshItem(k); err != nil {
+	if r.onDemandFetcher == nil && !r.IsReady() {
+		return *new(V), ErrNotReady
+	}
+	record, err := r.records().Get(ctx, k)
+	var decodeErr *driver.DecodeError
+	if err != nil && !errors.Is(err, driver.ErrNotFound) && !errors.As(err, &decodeErr) {
+		r.stats.driverErrors.Add(1)
+		r.log.Warn("Could not read record", zap.Any("Key", k), zap.Error(err))
+		return *new(V), fmt.Errorf("%w: %w", ErrDriverUnavailable, err)
+	}
+	// records which cannot be decoded are fetched again and overwritten
+	ok := err == nil
+	if !ok || r.isStale(record) {
+		r.stats.misses.Add(1)
+		if record, err = r.refreshItem(ctx, k, record, ok); err != nil {
 			return *new(V), err
 		}
+		return record.V, nil
 	}
-	record, ok := r.cache.Get(ctx, k)
-	if !ok {
-		return *new(V), fmt.Errorf("record not in cache")
-	}
+	r.stats.hits.Add(1)
 	return record.V, nil
 }
 
-func (r *RecordCache[K, V]) refreshAllRecords() {
+// Stats returns a snapshot of the cache counters.
+func (r *RecordCache[K, V]) Stats() Stats {
+	return r.stats.snapshot()
+}
+
+// refreshAllRecords loads every record with the AsyncFetcher. When a partial result is applied according to the partial
+// policy, err is nil and partial describes the records which failed to load.
+func (r *RecordCache[K, V]) refreshAllRecords() (partial error, err error) {
 	r.log.Info("Refreshing all records")
 	if r.asyncFetcher == nil {
-		return
+		return nil, nil
+	}
+	ctx := context.Background()
+	cursor, hasCursor := r.baselineCursor(ctx)
+	res := r.fetchAll(ctx)
+	if partial = res.err(); partial != nil {
+		r.reportError(ctx, "Could not refresh all records", partial)
+		if len(res.Records) == 0 || r.partialPolicy == PartialPolicyDiscard {
+			return nil, partial
+		}
+		if r.partialPolicy == PartialPolicyMerge {
+			// the cursor is not advanced, so that the next delta replays the changes to the records which failed to load
+			r.setAsyncRecords(res.Records)
+			r.log.Warn("Cache partially refreshed, merged loaded records", zap.Int("Loaded", len(res.Records)))
+			return partial, nil
+		}
 	}
-	latest, err := r.asyncFetcher.FetchAll(context.Background())
+	if r.guard != nil {
+		if err := r.guard.check(res.Records, r.previousAsyncCount(ctx)); err != nil {
+			r.reportError(ctx, "Refresh rejected, keeping existing records", err)
+			return nil, err
+		}
+	}
+	r.removeAsyncRecords(res.Records)
+	r.setAsyncRecords(res.Records)
+	r.saveAsyncCount(ctx, len(res.Records))
+	if hasCursor {
+		r.saveCursor(ctx, cursor)
+	}
+	r.log.Info("Cache refreshed")
+	return partial, nil
+}
+
+func (r *RecordCache[K, V]) fetchAll(ctx context.Context) PartialResult[K, V] {
+	release, err := r.acquireFetch(ctx)
 	if err != nil {
-		return
+		return PartialResult[K, V]{Err: err}
 	}
-	if !r.cache.Clear(context.Background()) {
-		r.log.Warn("could not empty cache when refreshing all")
+	defer release()
+	if f, ok := r.asyncFetcher.(PartialAsyncFetcher[K, V]); ok {
+		return f.FetchAllPartial(ctx)
 	}
+	latest, err := r.asyncFetcher.FetchAll(ctx)
+	return PartialResult[K, V]{Records: latest, Err: err}
+}
+
+func (r *RecordCache[K, V]) setAsyncRecords(latest map[K]V) {
 	now := time.Now()
 	for k, v := range latest {
-		r.cache.Set(context.Background(), k, RecordCacheItem[V]{V: v, T: now})
+		r.storeRecord(context.Background(), k, RecordCacheItem[V]{V: v, T: now, Async: true})
+	}
+}
+
+// reportError logs an error which occurred in the background and passes it to the error hooks.
+func (r *RecordCache[K, V]) reportError(ctx context.Context, msg string, err error) {
+	r.log.Error(msg, zap.Error(err))
+	for _, h := range r.errorHooks {
+		h(ctx, err)
+	}
+}
+
+// removeAsyncRecords removes records previously loaded by the AsyncFetcher that are not in latest. Without an
+// OnDemandFetcher every record is async, so the cache is simply cleared.
+func (r *RecordCache[K, V]) removeAsyncRecords(latest map[K]V) {
+	if r.onDemandFetcher == nil {
+		if !r.clearRecords(context.Background()) {
+			r.log.Warn("could not empty cache when refreshing all")
+		}
+		return
+	}
+	for k, v := range r.allRecords(context.Background()) {
+		if _, ok := latest[k]; v.Async && !ok {
+			r.untag(context.Background(), k, v)
+			r.records().Delete(context.Background(), k)
+		}
 	}
-	r.log.Info("Cache refreshed")
 }
 
 func (r *RecordCache[K, V]) removeStale() {
-	for k, v := range r.cache.All(context.Background()) {
-		if v.IsStale(r.recordTtl) {
-			r.cache.Delete(context.Background(), k)
+	for k, v := range r.allRecords(context.Background()) {
+		if r.isExpired(v) {
+			r.untag(context.Background(), k, v)
+			r.records().Delete(context.Background(), k)
 		}
 	}
 }
@@ -120,33 +435,161 @@ func (r *RecordCache[K, V]) refreshCache() {
 	if r.onDemandFetcher != nil {
 		r.removeStale()
 	}
-	if r.asyncFetcher != nil &&
-		(r.lastUpdated.IsZero() || !r.lastUpdated.After(time.Now().Truncate(asyncCacheCheckFrequency).Add(-1*r.allTtl))) {
-		r.refreshAllRecords()
-		r.lastUpdated = time.Now().Truncate(asyncCacheCheckFrequency)
+	if r.asyncFetcher != nil {
+		// errors are reported by refreshAllRecords
+		_ = r.refreshAsyncIfDue()
 	}
 }
 
-func (r *RecordCache[K, V]) refreshItem(k K) error {
-	if r.onDemandFetcher == nil {
-		return fmt.Errorf("value not in cache and on demand fetcher is not initalised")
+// refreshAsyncIfDue refreshes all records when the last successful refresh is older than the ttl. A failed refresh
+// leaves lastUpdated untouched so that it is retried on the next check, unless it was rejected by the refresh guard,
+// in which case it is retried after a backoff.
+func (r *RecordCache[K, V]) refreshAsyncIfDue() error {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+	if !r.lastUpdated.IsZero() && r.lastUpdated.After(time.Now().Truncate(asyncCacheCheckFrequency).Add(-1*r.allTtl)) {
+		return nil
 	}
-	r.log.Info("Refreshing record", zap.Any("key", k))
-	v, err := r.onDemandFetcher.FetchByKey(context.Background(), k)
+	if time.Now().Before(r.retryAt) {
+		return fmt.Errorf("refresh backed off until %s: %w", r.retryAt.Format(time.RFC3339), r.lastRejection)
+	}
+	return r.refreshAsync()
+}
+
+// nextRetry returns when a refresh rejected by the guard may be retried, or the zero time.
+func (r *RecordCache[K, V]) nextRetry() time.Time {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+	return r.retryAt
+}
+
+// Refresh reloads all records from the AsyncFetcher immediately, regardless of when they were last refreshed.
+func (r *RecordCache[K, V]) Refresh() error {
+	if r.asyncFetcher == nil {
+		return ErrNoAsyncFetcher
+	}
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+	return r.refreshAsync()
+}
+
+// refreshAsync refreshes all records and records the outcome. The caller must hold refreshMu.
+func (r *RecordCache[K, V]) refreshAsync() error {
+	start := time.Now()
+	partial, err := r.refreshAllRecords()
+	r.setRefreshStatus(start, partial, err)
+	r.backOffRejection(err)
 	if err != nil {
 		return err
 	}
-	r.cache.Set(context.Background(), k, RecordCacheItem[V]{V: v, T: time.Now()})
-	r.log.Debug("Refreshing record", zap.Any("Key", k), zap.Any("Value", v))
+	r.lastUpdated = time.Now().Truncate(asyncCacheCheckFrequency)
+	r.readiness.markReady()
 	return nil
 }
 
+// loadUntilReady retries the async load every retryInterval until the first successful load or the cache is closed.
+func (r *RecordCache[K, V]) loadUntilReady() {
+	retry := r.retryInterval
+	if retry <= 0 {
+		retry = defaultStartupRetryInterval
+	}
+	for !r.readiness.isReady() {
+		err := r.refreshAsyncIfDue()
+		if err == nil {
+			return
+		}
+		wait := max(retry, time.Until(r.nextRetry()))
+		r.log.Warn("Initial load failed", zap.Error(err), zap.String("RetryIn", wait.String()))
+		select {
+		case <-r.done:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// refreshItem fetches the record for k and stores it in the cache. current is the cached item, if cached is true,
+// which is passed to a RevalidatingFetcher. Concurrent requests for the same key share a single fetch, which is
+// detached from ctx so that it completes even if the caller which started it gives up.
+func (r *RecordCache[K, V]) refreshItem(ctx context.Context, k K, current RecordCacheItem[V], cached bool) (RecordCacheItem[V], error) {
+	if r.onDemandFetcher == nil {
+		return RecordCacheItem[V]{}, fmt.Errorf("value not in cache and on demand fetcher is not initalised")
+	}
+	f := r.flights.do(k, func(f *flight[V]) (RecordCacheItem[V], error) {
+		if op, ok := r.pendingWrite(k); ok {
+			return r.storePendingWrite(ctx, f, op)
+		}
+		fetchCtx, cancel := r.fetchContext(ctx)
+		defer cancel()
+		r.log.Info("Refreshing record", zap.Any("key", k))
+		item, err := r.fetchItem(fetchCtx, k, current, cached)
+		r.stats.fetched(item.Source, err)
+		r.observeFetch(err)
+		if err != nil {
+			return RecordCacheItem[V]{}, err
+		}
+		f.store(func() {
+			// a write queued during the fetch is stored by Set, and must not be overwritten by the previous value
+			if _, ok := r.pendingWrite(k); !ok {
+				r.storeRecord(context.WithoutCancel(ctx), k, item)
+			}
+		})
+		r.log.Debug("Refreshing record", zap.Any("Key", k), zap.Any("Value", item.V), zap.String("Source", item.Source))
+		return item, nil
+	})
+	select {
+	case <-f.done:
+		return f.item, f.err
+	case <-ctx.Done():
+		return RecordCacheItem[V]{}, ctx.Err()
+	}
+}
+
+func (r *RecordCache[K, V]) fetchItem(ctx context.Context, k K, current RecordCacheItem[V], cached bool) (RecordCacheItem[V], error) {
+	fetch := func(ctx context.Context) (RecordCacheItem[V], error) {
+		release, err := r.acquireFetch(ctx)
+		if err != nil {
+			return RecordCacheItem[V]{}, err
+		}
+		defer release()
+		start := time.Now()
+		item, err := r.fetchItemOnce(ctx, k, current, cached)
+		if r.hedging != nil {
+			// failed and cancelled attempts are observed too, as they are the slow ones: ignoring them would lower the
+			// hedge delay, and so raise the hedge rate, whenever the source slows down
+			r.hedging.latencies.observe(time.Since(start))
+		}
+		return item, err
+	}
+	if r.hedging != nil {
+		return r.hedgedFetch(ctx, fetch)
+	}
+	return fetch(ctx)
+}
+
+func (r *RecordCache[K, V]) fetchItemOnce(ctx context.Context, k K, current RecordCacheItem[V], cached bool) (RecordCacheItem[V], error) {
+	if f, ok := r.onDemandFetcher.(RevalidatingFetcher[K, V]); ok {
+		return r.revalidate(ctx, f, k, current, cached)
+	}
+	if f, ok := r.onDemandFetcher.(SourcedFetcher[K, V]); ok {
+		v, source, err := f.FetchByKeyWithSource(ctx, k)
+		if err != nil {
+			return RecordCacheItem[V]{}, err
+		}
+		return RecordCacheItem[V]{V: v, T: time.Now(), Source: source}, nil
+	}
+	v, err := r.onDemandFetcher.FetchByKey(ctx, k)
+	if err != nil {
+		return RecordCacheItem[V]{}, err
+	}
+	return RecordCacheItem[V]{V: v, T: time.Now()}, nil
+}
+
 func (r *RecordCache[K, V]) setSchedule() error {
 	if r.cron != nil {
 		r.log.Debug("Cron already set")
 		return nil
 	}
-	r.refreshCache()
 	r.cron = cron.New()
 	if _, err := r.cron.AddFunc("* * * * *", func() { r.refreshCache() }); err != nil {
 		return err
@@ -1,12 +1,31 @@
 package cache
 
-import "time"
+import (
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"time"
+)
 
 type RecordCacheItem[V any] struct {
 	V V
 	T time.Time
+	// Async is set when the record was loaded by an AsyncFetcher rather than an OnDemandFetcher, which determines the
+	// ttl applied to it in hybrid mode.
+	Async bool
+	// ETag and LastModified are the validators returned by a RevalidatingFetcher.
+	ETag         string
+	LastModified time.Time
+	// Source is the name of the fetcher which served the record, when fetched through a SourcedFetcher.
+	Source string
+	// Tags are the tags the record was indexed under when cached, used for tag based invalidation.
+	Tags []string
 }
 
 func (rci *RecordCacheItem[V]) IsStale(ttl time.Duration) bool {
 	return rci.T.Before(time.Now().Add(-1 * ttl))
 }
+
+// Size estimates the size of V with driver.SizeOf, so that the bounded memory drivers can limit the total size of the
+// records when given a max cost.
+func (rci RecordCacheItem[V]) Size() int64 {
+	return driver.SizeOf(rci.V)
+}
@@ -2,11 +2,15 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/ellogroup/ello-golang-cache/driver"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -280,3 +284,322 @@ func TestRecordCache_RefreshStaleRecordsEvery(t *testing.T) {
 		})
 	}
 }
+
+type flakyAsyncFetcherMock struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (f *flakyAsyncFetcherMock) FetchAll(_ context.Context) (map[string]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, fmt.Errorf("error")
+	}
+	return map[string]int{"active1": 1}, nil
+}
+
+func TestRecordCache_SetBackgroundLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		wantReady bool
+		want      int
+		wantErr   error
+	}{
+		{
+			name:      "ready after first successful load",
+			failures:  0,
+			wantReady: true,
+			want:      1,
+		},
+		{
+			name:      "ready after retrying failed loads",
+			failures:  2,
+			wantReady: true,
+			want:      1,
+		},
+		{
+			name:      "not ready while loads keep failing",
+			failures:  1000,
+			wantReady: false,
+			wantErr:   ErrNotReady,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &flakyAsyncFetcherMock{failures: tt.failures}
+			r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+				SetBackgroundLoad(time.Millisecond).
+				SetAsyncFetcher(f, 100*time.Second)
+			defer r.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			err := r.WaitReady(ctx)
+			if (err == nil) != tt.wantReady {
+				t.Fatalf("WaitReady() error = %v, wantReady %v", err, tt.wantReady)
+			}
+			if r.IsReady() != tt.wantReady {
+				t.Errorf("IsReady() = %v, want %v", r.IsReady(), tt.wantReady)
+			}
+
+			rec := httptest.NewRecorder()
+			r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			wantStatus := http.StatusServiceUnavailable
+			if tt.wantReady {
+				wantStatus = http.StatusOK
+			}
+			if rec.Code != wantStatus {
+				t.Errorf("ReadinessHandler() status = %v, want %v", rec.Code, wantStatus)
+			}
+
+			got, err := r.Get(context.Background(), "active1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCache_Ready(t *testing.T) {
+	t.Run("on demand cache is always ready", func(t *testing.T) {
+		r := NewRecordCache[string, int](newCacheStub()).SetOnDemandFetcher(newOnDemandFetcherMock(), 100*time.Second)
+		defer r.Close()
+		select {
+		case <-r.Ready():
+		default:
+			t.Errorf("Ready() not closed for on demand cache")
+		}
+	})
+	t.Run("synchronous load retries in background after failure", func(t *testing.T) {
+		f := &flakyAsyncFetcherMock{failures: 1}
+		r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]())
+		r.retryInterval = time.Millisecond
+		r.SetAsyncFetcher(f, 100*time.Second)
+		defer r.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		if err := r.WaitReady(ctx); err != nil {
+			t.Errorf("WaitReady() error = %v", err)
+		}
+	})
+}
+
+func TestRecordCache_SetHybridFetchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		k       string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "preloaded record served from async load",
+			k:    "active2",
+			want: 2,
+		},
+		{
+			name: "record missing from async load fetched on demand",
+			k:    "new",
+			want: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+				SetHybridFetchers(newAsyncFetcherMock(), 100*time.Second, newOnDemandFetcherMock(), 100*time.Second)
+			defer r.Close()
+			got, err := r.Get(context.Background(), tt.k)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCache_isStale(t *testing.T) {
+	r := &RecordCache[string, int]{
+		onDemandFetcher: newOnDemandFetcherMock(),
+		recordTtl:       10 * time.Second,
+		allTtl:          time.Hour,
+	}
+	tests := []struct {
+		name string
+		item RecordCacheItem[int]
+		want bool
+	}{
+		{
+			name: "async record within async ttl is not stale",
+			item: RecordCacheItem[int]{T: time.Now().Add(-time.Minute), Async: true},
+			want: false,
+		},
+		{
+			name: "async record beyond async ttl is stale",
+			item: RecordCacheItem[int]{T: time.Now().Add(-2 * time.Hour), Async: true},
+			want: true,
+		},
+		{
+			name: "on demand record beyond record ttl is stale",
+			item: RecordCacheItem[int]{T: time.Now().Add(-time.Minute)},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.isStale(tt.item); got != tt.want {
+				t.Errorf("isStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordCache_refreshAllRecordsHybrid(t *testing.T) {
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	c.Set(context.Background(), "removed", RecordCacheItem[int]{V: 5, T: time.Now(), Async: true})
+	c.Set(context.Background(), "on_demand", RecordCacheItem[int]{V: 6, T: time.Now()})
+	r := &RecordCache[string, int]{
+		log:             zap.NewNop(),
+		cache:           c,
+		onDemandFetcher: newOnDemandFetcherMock(),
+		asyncFetcher:    newAsyncFetcherMock(),
+	}
+	if _, err := r.refreshAllRecords(); err != nil {
+		t.Fatalf("refreshAllRecords() error = %v", err)
+	}
+	if c.Has(context.Background(), "removed") {
+		t.Errorf("async record missing from latest load was not removed")
+	}
+	if !c.Has(context.Background(), "on_demand") {
+		t.Errorf("on demand record was removed by full load")
+	}
+	if v, _ := c.Get(context.Background(), "active1"); !v.Async {
+		t.Errorf("record from full load not marked async")
+	}
+}
+
+// TestRecordCache_concurrentRefresh runs the minute refresh of the cron alongside request goroutines, and is
+// meaningful when run with the race detector.
+func TestRecordCache_concurrentRefresh(t *testing.T) {
+	c := driver.NewMemoryCache[int, RecordCacheItem[int]]()
+	r := NewRecordCache[int, int](c).SetHybridFetchers(
+		AsyncFetcherFunc[int, int](func(context.Context) (map[int]int, error) {
+			return map[int]int{1: 1, 2: 2}, nil
+		}), time.Hour,
+		OnDemandFetcherFunc[int, int](func(_ context.Context, k int) (int, error) {
+			return k, nil
+		}), time.Nanosecond,
+	)
+	defer r.Close()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range 200 {
+				if v, err := r.Get(context.Background(), k%20); err != nil || v != k%20 {
+					t.Errorf("Get() = %v, %v, want %v", v, err, k%20)
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			r.refreshCache()
+			_ = r.Refresh()
+		}
+	}()
+	wg.Wait()
+}
+
+func TestRecordCacheItem_Size(t *testing.T) {
+	if got := (RecordCacheItem[string]{V: "abc"}).Size(); got != 3 {
+		t.Errorf("Size() = %v, want 3", got)
+	}
+	if got := (RecordCacheItem[int]{V: 42}).Size(); got != 1 {
+		t.Errorf("Size() = %v, want 1", got)
+	}
+}
+
+type cacheV2Stub struct {
+	driver.CacheV2[string, RecordCacheItem[int]]
+	err error
+}
+
+func (c cacheV2Stub) Get(ctx context.Context, k string) (RecordCacheItem[int], error) {
+	if c.err != nil {
+		return RecordCacheItem[int]{}, c.err
+	}
+	return c.CacheV2.Get(ctx, k)
+}
+
+type countingFetcher struct {
+	calls int
+}
+
+func (f *countingFetcher) FetchByKey(_ context.Context, _ string) (int, error) {
+	f.calls++
+	return 10, nil
+}
+
+func TestRecordCache_GetDriverErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		want        int
+		wantErr     error
+		wantFetches int
+		wantErrors  uint64
+	}{
+		{
+			name:       "backend error",
+			err:        &driver.BackendError{Op: "get", Err: errors.New("connection refused")},
+			wantErr:    ErrDriverUnavailable,
+			wantErrors: 1,
+		},
+		{
+			name:        "decode error",
+			err:         &driver.DecodeError{Err: errors.New("bad gob")},
+			want:        10,
+			wantFetches: 1,
+		},
+		{
+			name:        "not found",
+			err:         driver.ErrNotFound,
+			want:        10,
+			wantFetches: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &countingFetcher{}
+			stub := cacheV2Stub{CacheV2: driver.FromV1(newCacheStub()), err: tt.err}
+			r := NewRecordCacheV2[string, int](stub).SetOnDemandFetcher(f, time.Hour)
+			got, err := r.Get(context.Background(), "active1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+			if f.calls != tt.wantFetches {
+				t.Errorf("fetcher called %v times, want %v", f.calls, tt.wantFetches)
+			}
+			if got := r.Stats().DriverErrors; got != tt.wantErrors {
+				t.Errorf("Stats().DriverErrors = %v, want %v", got, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Info describes a registered cache, as shown by the admin handler.
+type Info struct {
+	Name        string        `json:"name"`
+	Ready       bool          `json:"ready"`
+	Config      Config        `json:"config"`
+	Stats       Stats         `json:"stats"`
+	LastRefresh RefreshStatus `json:"lastRefresh"`
+}
+
+// Config summarises how a cache has been configured.
+type Config struct {
+	OnDemand       bool          `json:"onDemand"`
+	Async          bool          `json:"async"`
+	Delta          bool          `json:"delta"`
+	RecordTtl      time.Duration `json:"recordTtl"`
+	AsyncTtl       time.Duration `json:"asyncTtl"`
+	StaleRetention time.Duration `json:"staleRetention"`
+	FetchTimeout   time.Duration `json:"fetchTimeout"`
+	PartialPolicy  PartialPolicy `json:"partialPolicy"`
+	Guarded        bool          `json:"guarded"`
+	FetchLimits    bool          `json:"fetchLimits"`
+	Hedging        bool          `json:"hedging"`
+	Writer         bool          `json:"writer"`
+	WriteBehind    bool          `json:"writeBehind"`
+	Tagged         bool          `json:"tagged"`
+}
+
+// RefreshStatus is the outcome of the last full refresh by the AsyncFetcher. It is zero until the first refresh.
+type RefreshStatus struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration"`
+	Records  int           `json:"records"`
+	Error    string        `json:"error,omitempty"`
+	// Partial is set when the refresh applied a partial result, Error describing the records which failed to load.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Registry holds named caches so that they can be inspected and managed, for example through the admin handler.
+type Registry struct {
+	mu     sync.RWMutex
+	caches map[string]registered
+}
+
+// registered is implemented by the caches held in a Registry, taking keys as strings so that they can be given in a
+// URL.
+type registered interface {
+	Info() Info
+	Health(ctx context.Context) Health
+	lookup(ctx context.Context, key string) (entry, bool, error)
+	invalidate(ctx context.Context, key string) (bool, error)
+	InvalidateTag(ctx context.Context, tag string) int
+	Refresh() error
+}
+
+type entry struct {
+	Key          string    `json:"key"`
+	Value        any       `json:"value"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+	Stale        bool      `json:"stale"`
+	Async        bool      `json:"async"`
+	Source       string    `json:"source,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"lastModified,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+}
+
+func NewRegistry() *Registry {
+	return &Registry{caches: map[string]registered{}}
+}
+
+// Names returns the names of the registered caches, sorted.
+func (g *Registry) Names() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	names := make([]string, 0, len(g.caches))
+	for name := range g.caches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Info returns the description of every registered cache, sorted by name.
+func (g *Registry) Info() []Info {
+	infos := []Info{}
+	for _, name := range g.Names() {
+		if c, ok := g.get(name); ok {
+			infos = append(infos, c.Info())
+		}
+	}
+	return infos
+}
+
+// Unregister removes the named cache from the registry.
+func (g *Registry) Unregister(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.caches, name)
+}
+
+func (g *Registry) register(name string, c registered) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.caches[name] = c
+}
+
+func (g *Registry) get(name string) (registered, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	c, ok := g.caches[name]
+	return c, ok
+}
+
+// Register adds the cache to reg under name, replacing any cache already registered with that name.
+func (r *RecordCache[K, V]) Register(reg *Registry, name string) *RecordCache[K, V] {
+	r.name = name
+	reg.register(name, r)
+	return r
+}
+
+// Info describes the configuration and current state of the cache.
+func (r *RecordCache[K, V]) Info() Info {
+	r.statusMu.Lock()
+	last := r.lastRefresh
+	r.statusMu.Unlock()
+	return Info{
+		Name:        r.name,
+		Ready:       r.IsReady(),
+		Config:      r.config(),
+		Stats:       r.Stats(),
+		LastRefresh: last,
+	}
+}
+
+// Invalidate removes the record for k from the cache, so that it is fetched again when next requested.
+func (r *RecordCache[K, V]) Invalidate(ctx context.Context, k K) bool {
+	return r.deleteRecord(ctx, k)
+}
+
+func (r *RecordCache[K, V]) config() Config {
+	return Config{
+		OnDemand:       r.onDemandFetcher != nil,
+		Async:          r.asyncFetcher != nil,
+		Delta:          r.deltaFetcher != nil,
+		RecordTtl:      r.recordTtl,
+		AsyncTtl:       r.allTtl,
+		StaleRetention: r.staleRetention,
+		FetchTimeout:   r.fetchTimeout,
+		PartialPolicy:  r.partialPolicy,
+		Guarded:        r.guard != nil,
+		FetchLimits:    r.limiter != nil,
+		Hedging:        r.hedging != nil,
+		Writer:         r.writer != nil,
+		WriteBehind:    r.writeBehind != nil,
+		Tagged:         r.tagger != nil,
+	}
+}
+
+// setRefreshStatus records the outcome of a refresh. A partial result which was applied counts as a success, while
+// still being reported.
+func (r *RecordCache[K, V]) setRefreshStatus(start time.Time, partial error, err error) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+	r.lastRefresh = RefreshStatus{At: start, Duration: time.Since(start), Records: r.asyncCount}
+	switch {
+	case err != nil:
+		r.lastRefresh.Error = err.Error()
+	case partial != nil:
+		r.lastRefresh.Error = partial.Error()
+		r.lastRefresh.Partial = true
+		r.lastSuccess = start
+	default:
+		r.lastSuccess = start
+	}
+}
+
+func (r *RecordCache[K, V]) lookup(ctx context.Context, key string) (entry, bool, error) {
+	k, err := ParseKey[K](key)
+	if err != nil {
+		return entry{}, false, err
+	}
+	item, err := r.records().Get(ctx, k)
+	if errors.Is(err, driver.ErrNotFound) {
+		return entry{}, false, nil
+	}
+	if err != nil {
+		return entry{}, false, fmt.Errorf("%w: %w", ErrDriverUnavailable, err)
+	}
+	return entry{
+		Key:          key,
+		Value:        item.V,
+		FetchedAt:    item.T,
+		Stale:        r.isStale(item),
+		Async:        item.Async,
+		Source:       item.Source,
+		ETag:         item.ETag,
+		LastModified: item.LastModified,
+		Tags:         item.Tags,
+	}, true, nil
+}
+
+func (r *RecordCache[K, V]) invalidate(ctx context.Context, key string) (bool, error) {
+	k, err := ParseKey[K](key)
+	if err != nil {
+		return false, err
+	}
+	return r.Invalidate(ctx, k), nil
+}
+
+// ParseKey decodes a key given as a string, such as in a URL or on the command line. String keys are used as is, keys
+// implementing encoding.TextUnmarshaler are decoded with it, and any other key is decoded as JSON, e.g. 42 or
+// {"Id":"a"}.
+func ParseKey[K comparable](s string) (K, error) {
+	var k K
+	switch p := any(&k).(type) {
+	case *string:
+		*p = s
+		return k, nil
+	case encoding.TextUnmarshaler:
+		if err := p.UnmarshalText([]byte(s)); err != nil {
+			return k, fmt.Errorf("invalid key %q: %w", s, err)
+		}
+		return k, nil
+	}
+	if err := json.Unmarshal([]byte(s), &k); err != nil {
+		return k, fmt.Errorf("invalid key %q: %w", s, err)
+	}
+	return k, nil
+}
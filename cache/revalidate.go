@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Revalidation is returned by a RevalidatingFetcher. When NotModified is set the cached record is kept and only its
+// timestamp is bumped, otherwise V replaces it along with the new validators.
+type Revalidation[V any] struct {
+	V            V
+	NotModified  bool
+	ETag         string
+	LastModified time.Time
+}
+
+func (r *RecordCache[K, V]) revalidate(ctx context.Context, f RevalidatingFetcher[K, V], k K, current RecordCacheItem[V], cached bool) (RecordCacheItem[V], error) {
+	if !cached {
+		current = RecordCacheItem[V]{}
+	}
+	rv, err := f.FetchIfModified(ctx, k, current)
+	if err != nil {
+		return RecordCacheItem[V]{}, err
+	}
+	if rv.NotModified {
+		if !cached {
+			return RecordCacheItem[V]{}, fmt.Errorf("not modified response for record not in cache")
+		}
+		r.log.Debug("Record not modified", zap.Any("Key", k))
+		current.T = time.Now()
+		current.Async = false
+		return current, nil
+	}
+	return RecordCacheItem[V]{V: rv.V, T: time.Now(), ETag: rv.ETag, LastModified: rv.LastModified}, nil
+}
+
+type revalidatingFetcher[K comparable, V any] struct {
+	f RevalidatingFetcher[K, V]
+}
+
+func (r revalidatingFetcher[K, V]) FetchByKey(ctx context.Context, k K) (V, error) {
+	rv, err := r.f.FetchIfModified(ctx, k, RecordCacheItem[V]{})
+	if err == nil && rv.NotModified {
+		err = fmt.Errorf("not modified response without validators")
+	}
+	return rv.V, err
+}
+
+func (r revalidatingFetcher[K, V]) FetchIfModified(ctx context.Context, k K, current RecordCacheItem[V]) (Revalidation[V], error) {
+	return r.f.FetchIfModified(ctx, k, current)
+}
+
+func newRevalidatingFetcher[K comparable, V any](f RevalidatingFetcher[K, V]) revalidatingFetcher[K, V] {
+	return revalidatingFetcher[K, V]{f: f}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+type revalidatingFetcherMock struct {
+	got RecordCacheItem[int]
+}
+
+func (m *revalidatingFetcherMock) FetchIfModified(_ context.Context, _ string, current RecordCacheItem[int]) (Revalidation[int], error) {
+	m.got = current
+	if current.ETag == "v1" {
+		return Revalidation[int]{NotModified: true}, nil
+	}
+	return Revalidation[int]{V: 20, ETag: "v2"}, nil
+}
+
+func TestRecordCache_SetRevalidatingFetcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		cached   *RecordCacheItem[int]
+		want     int
+		wantETag string
+		sentETag string
+	}{
+		{
+			name:     "stale record not modified keeps value",
+			cached:   &RecordCacheItem[int]{V: 1, T: time.Now().Add(-time.Hour), ETag: "v1"},
+			want:     1,
+			wantETag: "v1",
+			sentETag: "v1",
+		},
+		{
+			name:     "stale record modified replaces value",
+			cached:   &RecordCacheItem[int]{V: 1, T: time.Now().Add(-time.Hour), ETag: "v0"},
+			want:     20,
+			wantETag: "v2",
+			sentETag: "v0",
+		},
+		{
+			name:     "uncached record fetched without validators",
+			want:     20,
+			wantETag: "v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+			if tt.cached != nil {
+				c.Set(context.Background(), "k", *tt.cached)
+			}
+			f := &revalidatingFetcherMock{}
+			r := &RecordCache[string, int]{
+				log:             zap.NewNop(),
+				cache:           c,
+				onDemandFetcher: newRevalidatingFetcher[string, int](f),
+				recordTtl:       time.Minute,
+			}
+			got, err := r.Get(context.Background(), "k")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() got = %v, want %v", got, tt.want)
+			}
+			if f.got.ETag != tt.sentETag {
+				t.Errorf("FetchIfModified() received ETag = %v, want %v", f.got.ETag, tt.sentETag)
+			}
+			item, _ := c.Get(context.Background(), "k")
+			if item.ETag != tt.wantETag || item.IsStale(time.Minute) {
+				t.Errorf("cached item = %+v, want fresh with ETag %v", item, tt.wantETag)
+			}
+		})
+	}
+}
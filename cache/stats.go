@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Stats are counters describing the activity of a RecordCache since it was created.
+type Stats struct {
+	Hits        uint64
+	Misses      uint64
+	Fetches     uint64
+	FetchErrors uint64
+	// DriverErrors counts the reads which failed because the driver was unavailable.
+	DriverErrors uint64
+	// Hedges counts the second fetches issued by hedging.
+	Hedges uint64
+	// Sources counts the records fetched on demand by the name of the source which served them.
+	Sources map[string]uint64
+}
+
+type stats struct {
+	hits         atomic.Uint64
+	misses       atomic.Uint64
+	fetches      atomic.Uint64
+	fetchErrors  atomic.Uint64
+	hedges       atomic.Uint64
+	driverErrors atomic.Uint64
+	mu           sync.Mutex
+	sources      map[string]uint64
+}
+
+func (s *stats) fetched(source string, err error) {
+	s.fetches.Add(1)
+	if err != nil {
+		s.fetchErrors.Add(1)
+		return
+	}
+	if source == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sources == nil {
+		s.sources = map[string]uint64{}
+	}
+	s.sources[source]++
+}
+
+func (s *stats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sources := make(map[string]uint64, len(s.sources))
+	for k, v := range s.sources {
+		sources[k] = v
+	}
+	return Stats{
+		Hits:         s.hits.Load(),
+		Misses:       s.misses.Load(),
+		Fetches:      s.fetches.Load(),
+		FetchErrors:  s.fetchErrors.Load(),
+		DriverErrors: s.driverErrors.Load(),
+		Hedges:       s.hedges.Load(),
+		Sources:      sources,
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"go.uber.org/zap"
+	"slices"
+)
+
+// SetTagger sets a func returning the tags of a record whenever it is cached, allowing every record carrying a tag to
+// be invalidated with InvalidateTag. The tag index is kept in the driver when it implements driver.TagIndex, and in
+// memory otherwise.
+func (r *RecordCache[K, V]) SetTagger(f func(k K, v V) []string) *RecordCache[K, V] {
+	r.tagger = f
+	if ti, ok := r.backend().(driver.TagIndex[K]); ok {
+		r.tags = ti
+	} else {
+		r.tags = driver.NewMemoryTagIndex[K]()
+	}
+	return r
+}
+
+// InvalidateTag removes every record carrying tag from the cache, returning the number of records removed.
+func (r *RecordCache[K, V]) InvalidateTag(ctx context.Context, tag string) int {
+	if r.tags == nil {
+		return 0
+	}
+	n := 0
+	for _, k := range r.tags.TaggedKeys(ctx, tag) {
+		item, ok := r.getRecord(ctx, k)
+		if !ok || !slices.Contains(item.Tags, tag) {
+			// the record has been replaced by one without the tag
+			r.tags.RemoveTags(ctx, k, []string{tag})
+			continue
+		}
+		if r.records().Delete(ctx, k) == nil {
+			n++
+		}
+		r.tags.RemoveTags(ctx, k, item.Tags)
+	}
+	r.log.Debug("Tag invalidated", zap.String("Tag", tag), zap.Int("Count", n))
+	return n
+}
+
+// storeRecord stores item in the cache, tagging it when a tagger is set. Tags of the record it replaces which item no
+// longer carries are removed from the index.
+func (r *RecordCache[K, V]) storeRecord(ctx context.Context, k K, item RecordCacheItem[V]) bool {
+	if r.tagger != nil {
+		item.Tags = r.tagger(k, item.V)
+		if prev, ok := r.getRecord(ctx, k); ok {
+			stale := slices.DeleteFunc(slices.Clone(prev.Tags), func(tag string) bool { return slices.Contains(item.Tags, tag) })
+			if len(stale) > 0 && !r.tags.RemoveTags(ctx, k, stale) {
+				r.log.Warn("could not remove replaced record tags", zap.Any("Key", k))
+			}
+		}
+		if len(item.Tags) > 0 && !r.tags.AddTags(ctx, k, item.Tags) {
+			r.log.Warn("could not index record tags", zap.Any("Key", k))
+		}
+	}
+	if err := r.records().Set(ctx, k, item); err != nil {
+		r.log.Warn("could not store record", zap.Any("Key", k), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// deleteRecord deletes the record for k from the cache and the tag index.
+func (r *RecordCache[K, V]) deleteRecord(ctx context.Context, k K) bool {
+	if r.tagger != nil {
+		if item, ok := r.getRecord(ctx, k); ok {
+			r.untag(ctx, k, item)
+		}
+	}
+	return r.records().Delete(ctx, k) == nil
+}
+
+// getRecord returns the record for k, if it could be read from the cache.
+func (r *RecordCache[K, V]) getRecord(ctx context.Context, k K) (RecordCacheItem[V], bool) {
+	item, err := r.records().Get(ctx, k)
+	return item, err == nil
+}
+
+// allRecords returns every record which could be read from the cache, reporting a driver failure.
+func (r *RecordCache[K, V]) allRecords(ctx context.Context) map[K]RecordCacheItem[V] {
+	all, err := r.records().All(ctx)
+	if err != nil {
+		r.reportError(ctx, "Could not read records", err)
+	}
+	return all
+}
+
+// untag removes a record known to be about to be deleted from the tag index.
+func (r *RecordCache[K, V]) untag(ctx context.Context, k K, item RecordCacheItem[V]) {
+	if r.tags != nil && len(item.Tags) > 0 {
+		r.tags.RemoveTags(ctx, k, item.Tags)
+	}
+}
+
+// clearRecords empties the cache and the tag index.
+func (r *RecordCache[K, V]) clearRecords(ctx context.Context) bool {
+	if r.tags != nil && !r.tags.ClearTags(ctx) {
+		r.log.Warn("could not clear tag index")
+	}
+	return r.records().Clear(ctx) == nil
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"testing"
+	"time"
+)
+
+func tenantTagger(k string, v int) []string {
+	return []string{fmt.Sprintf("tenant:%d", v%2), "key:" + k}
+}
+
+func TestRecordCache_InvalidateTag(t *testing.T) {
+	tests := []struct {
+		name      string
+		tag       string
+		want      int
+		wantGone  []string
+		wantStays []string
+	}{
+		{
+			name:      "invalidates every record carrying tag",
+			tag:       "tenant:1",
+			want:      2,
+			wantGone:  []string{"a", "c"},
+			wantStays: []string{"b"},
+		},
+		{
+			name:      "unknown tag invalidates nothing",
+			tag:       "tenant:9",
+			want:      0,
+			wantStays: []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+			r := NewRecordCache[string, int](c).SetTagger(tenantTagger).SetWriter(newWriterMock(0))
+			for k, v := range map[string]int{"a": 1, "b": 2, "c": 3} {
+				if err := r.Set(context.Background(), k, v); err != nil {
+					t.Fatalf("Set() error = %v", err)
+				}
+			}
+			if got := r.InvalidateTag(context.Background(), tt.tag); got != tt.want {
+				t.Errorf("InvalidateTag() = %v, want %v", got, tt.want)
+			}
+			for _, k := range tt.wantGone {
+				if c.Has(context.Background(), k) {
+					t.Errorf("record %v still cached", k)
+				}
+			}
+			for _, k := range tt.wantStays {
+				if !c.Has(context.Background(), k) {
+					t.Errorf("record %v not cached", k)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordCache_InvalidateTagRetagged(t *testing.T) {
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	r := NewRecordCache[string, int](c).SetTagger(tenantTagger).SetWriter(newWriterMock(0))
+	_ = r.Set(context.Background(), "a", 1)
+	_ = r.Set(context.Background(), "a", 2)
+	if keys := c.TaggedKeys(context.Background(), "tenant:1"); len(keys) != 0 {
+		t.Errorf("TaggedKeys() of replaced tag = %v, want empty", keys)
+	}
+	if keys := c.TaggedKeys(context.Background(), "key:a"); len(keys) != 1 {
+		t.Errorf("TaggedKeys() of kept tag = %v, want [a]", keys)
+	}
+	if got := r.InvalidateTag(context.Background(), "tenant:1"); got != 0 {
+		t.Errorf("InvalidateTag() of replaced tag = %v, want 0", got)
+	}
+	if !c.Has(context.Background(), "a") {
+		t.Errorf("record with replaced tag was invalidated")
+	}
+}
+
+func TestRecordCache_removeStaleUntags(t *testing.T) {
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	r := NewRecordCache[string, int](c).SetTagger(tenantTagger)
+	r.onDemandFetcher = newOnDemandFetcherMock()
+	r.recordTtl = time.Minute
+	r.storeRecord(context.Background(), "a", RecordCacheItem[int]{V: 1, T: time.Now().Add(-time.Hour)})
+	r.removeStale()
+	if keys := c.TaggedKeys(context.Background(), "tenant:1"); len(keys) != 0 {
+		t.Errorf("TaggedKeys() after removeStale = %v, want empty", keys)
+	}
+}
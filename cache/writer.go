@@ -0,0 +1,335 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// WriteOp is a pending write in write-behind mode. Delete is set when the record is to be deleted, in which case
+// Value is the zero value.
+type WriteOp[K comparable, V any] struct {
+	Key    K
+	Value  V
+	Delete bool
+}
+
+// WriteBehindConfig configures write-behind mode. Zero values use the defaults.
+type WriteBehindConfig struct {
+	// FlushInterval is how often pending writes are flushed. Defaults to 1 second.
+	FlushInterval time.Duration
+	// BatchSize is the maximum number of writes per batch; reaching it triggers a flush. Defaults to 100.
+	BatchSize int
+	// MaxRetries is the number of times a failed batch is retried before it is dropped and reported. Defaults to 3.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubled for each following one. Defaults to 100 milliseconds.
+	RetryBackoff time.Duration
+	// CloseTimeout bounds how long Close waits for pending writes to be flushed. Writes not applied by then are dropped
+	// and reported. Defaults to 10 seconds.
+	CloseTimeout time.Duration
+}
+
+// WriteBehindError is reported to the error hooks when a batch of writes is dropped after exhausting its retries.
+type WriteBehindError[K comparable, V any] struct {
+	Ops []WriteOp[K, V]
+	Err error
+}
+
+func (e *WriteBehindError[K, V]) Error() string {
+	return fmt.Sprintf("write behind: dropped %d writes: %s", len(e.Ops), e.Err)
+}
+
+func (e *WriteBehindError[K, V]) Unwrap() error {
+	return e.Err
+}
+
+type writeBehind[K comparable, V any] struct {
+	cfg     WriteBehindConfig
+	mu      sync.Mutex
+	pending map[K]WriteOp[K, V]
+	order   []K
+	// applying holds the writes taken from pending which are being applied, so that reads still see them
+	applying map[K]WriteOp[K, V]
+	closed   bool
+	flush    chan struct{}
+	stopped  chan struct{}
+}
+
+func newWriteBehind[K comparable, V any](cfg WriteBehindConfig) *writeBehind[K, V] {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	if cfg.CloseTimeout <= 0 {
+		cfg.CloseTimeout = 10 * time.Second
+	}
+	return &writeBehind[K, V]{
+		cfg:      cfg,
+		pending:  map[K]WriteOp[K, V]{},
+		applying: map[K]WriteOp[K, V]{},
+		flush:    make(chan struct{}, 1),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// enqueue adds op to the pending writes, replacing any pending write for the same key. Returns ErrClosed once the
+// cache is closed, as the write would never be applied.
+func (w *writeBehind[K, V]) enqueue(op WriteOp[K, V]) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	if _, ok := w.pending[op.Key]; !ok {
+		w.order = append(w.order, op.Key)
+	}
+	w.pending[op.Key] = op
+	full := len(w.order) >= w.cfg.BatchSize
+	w.mu.Unlock()
+	if full {
+		select {
+		case w.flush <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// close stops further writes from being queued. Writes queued before are still flushed.
+func (w *writeBehind[K, V]) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+}
+
+// take removes and returns up to BatchSize pending writes in the order they were first queued. They remain visible to
+// lookup until passed to applied.
+func (w *writeBehind[K, V]) take() []WriteOp[K, V] {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := min(len(w.order), w.cfg.BatchSize)
+	ops := make([]WriteOp[K, V], 0, n)
+	for _, k := range w.order[:n] {
+		ops = append(ops, w.pending[k])
+		w.applying[k] = w.pending[k]
+		delete(w.pending, k)
+	}
+	w.order = w.order[n:]
+	return ops
+}
+
+// applied removes ops taken by take, once they have been applied or dropped.
+func (w *writeBehind[K, V]) applied(ops []WriteOp[K, V]) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, op := range ops {
+		delete(w.applying, op.Key)
+	}
+}
+
+// lookup returns the latest write to k which has not yet been applied to the source.
+func (w *writeBehind[K, V]) lookup(k K) (WriteOp[K, V], bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if op, ok := w.pending[k]; ok {
+		return op, true
+	}
+	op, ok := w.applying[k]
+	return op, ok
+}
+
+// pendingWrite returns the write to k which has not yet been applied to the source in write-behind mode. The source
+// still holds the previous value, so reads must be served from the pending write rather than fetched.
+func (r *RecordCache[K, V]) pendingWrite(k K) (WriteOp[K, V], bool) {
+	if r.writeBehind == nil {
+		return WriteOp[K, V]{}, false
+	}
+	return r.writeBehind.lookup(k)
+}
+
+// storePendingWrite serves a read of a key with a write not yet applied to the source: a pending delete is not found,
+// and a pending value is stored again, as it may have been evicted.
+func (r *RecordCache[K, V]) storePendingWrite(ctx context.Context, f *flight[V], op WriteOp[K, V]) (RecordCacheItem[V], error) {
+	if op.Delete {
+		return RecordCacheItem[V]{}, ErrNotFound
+	}
+	item := RecordCacheItem[V]{V: op.Value, T: time.Now()}
+	f.store(func() { r.storeRecord(context.WithoutCancel(ctx), op.Key, item) })
+	return item, nil
+}
+
+// SetWriter enables write-through: Set and Delete write to w before updating the cache.
+func (r *RecordCache[K, V]) SetWriter(w Writer[K, V]) *RecordCache[K, V] {
+	r.writer = w
+	return r
+}
+
+// SetWriteBehind enables write-behind: Set and Delete update the cache immediately, and the writes are batched and
+// applied to w in the background, retrying failed batches. Until a write is applied, reads of its key are served from
+// it rather than fetched from the source. Pending writes are flushed by Close, after which Set and Delete return
+// ErrClosed.
+func (r *RecordCache[K, V]) SetWriteBehind(w Writer[K, V], cfg WriteBehindConfig) *RecordCache[K, V] {
+	r.writer = w
+	r.writeBehind = newWriteBehind[K, V](cfg)
+	if r.done == nil {
+		r.done = make(chan struct{})
+	}
+	go r.runWriteBehind()
+	return r
+}
+
+// Set writes v to the source and stores it in the cache. In write-behind mode the write to the source happens in the
+// background.
+func (r *RecordCache[K, V]) Set(ctx context.Context, k K, v V) error {
+	if r.writer == nil {
+		return ErrNoWriter
+	}
+	item := RecordCacheItem[V]{V: v, T: time.Now()}
+	if r.writeBehind != nil {
+		// the write is queued first, so that a fetch starting from here on is served from it
+		if err := r.writeBehind.enqueue(WriteOp[K, V]{Key: k, Value: v}); err != nil {
+			return err
+		}
+		r.flights.invalidate(k)
+		if !r.storeRecord(ctx, k, item) {
+			return fmt.Errorf("could not store record in cache")
+		}
+		return nil
+	}
+	if err := r.writer.Write(ctx, k, v); err != nil {
+		return err
+	}
+	// a fetch started before the write may return the previous value, so it must not overwrite the new one
+	r.flights.invalidate(k)
+	if !r.storeRecord(ctx, k, item) {
+		// the source has changed, so the cached record must not be served
+		r.deleteRecord(ctx, k)
+		return fmt.Errorf("could not store record in cache")
+	}
+	return nil
+}
+
+// Delete deletes the record from the source and the cache. In write-behind mode the delete from the source happens in
+// the background.
+func (r *RecordCache[K, V]) Delete(ctx context.Context, k K) error {
+	if r.writer == nil {
+		return ErrNoWriter
+	}
+	if r.writeBehind != nil {
+		if err := r.writeBehind.enqueue(WriteOp[K, V]{Key: k, Delete: true}); err != nil {
+			return err
+		}
+		r.flights.invalidate(k)
+		r.deleteRecord(ctx, k)
+		return nil
+	}
+	if err := r.writer.Delete(ctx, k); err != nil {
+		return err
+	}
+	r.flights.invalidate(k)
+	if !r.deleteRecord(ctx, k) {
+		return fmt.Errorf("could not delete record from cache")
+	}
+	return nil
+}
+
+func (r *RecordCache[K, V]) runWriteBehind() {
+	defer close(r.writeBehind.stopped)
+	// ctx is cancelled once the cache has been closed for CloseTimeout, interrupting the writes still being flushed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case <-time.After(r.writeBehind.cfg.CloseTimeout):
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	t := time.NewTicker(r.writeBehind.cfg.FlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-r.writeBehind.flush:
+		case <-r.done:
+			r.flushWrites(ctx)
+			return
+		}
+		r.flushWrites(ctx)
+	}
+}
+
+// flushWrites applies every pending write in batches. Once ctx is done, the remaining writes are dropped and reported.
+func (r *RecordCache[K, V]) flushWrites(ctx context.Context) {
+	for ops := r.writeBehind.take(); len(ops) > 0; ops = r.writeBehind.take() {
+		failed, err := r.writeBatch(ctx, ops)
+		r.writeBehind.applied(ops)
+		if err != nil {
+			r.reportError(context.WithoutCancel(ctx), "Could not write records", &WriteBehindError[K, V]{Ops: failed, Err: err})
+			continue
+		}
+		r.log.Debug("Records written", zap.Int("Count", len(ops)))
+	}
+}
+
+// writeBatch applies ops, retrying with exponential backoff. On failure, the writes which could not be applied are
+// returned.
+func (r *RecordCache[K, V]) writeBatch(ctx context.Context, ops []WriteOp[K, V]) ([]WriteOp[K, V], error) {
+	var err error
+	wait := r.writeBehind.cfg.RetryBackoff
+	for i := 0; i <= r.writeBehind.cfg.MaxRetries; i++ {
+		if err := ctx.Err(); err != nil {
+			return ops, err
+		}
+		if i > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ops, ctx.Err()
+			}
+			wait *= 2
+		}
+		if ops, err = r.applyWrites(ctx, ops); err == nil {
+			return nil, nil
+		}
+		r.log.Warn("Write failed", zap.Error(err), zap.Int("Attempt", i+1))
+	}
+	return ops, err
+}
+
+// applyWrites applies ops, returning those which have not been applied on failure.
+func (r *RecordCache[K, V]) applyWrites(ctx context.Context, ops []WriteOp[K, V]) ([]WriteOp[K, V], error) {
+	if bw, ok := r.writer.(BatchWriter[K, V]); ok {
+		if err := bw.WriteBatch(ctx, ops); err != nil {
+			return ops, err
+		}
+		return nil, nil
+	}
+	for i, op := range ops {
+		var err error
+		if op.Delete {
+			err = r.writer.Delete(ctx, op.Key)
+		} else {
+			err = r.writer.Write(ctx, op.Key, op.Value)
+		}
+		if err != nil {
+			return ops[i:], err
+		}
+	}
+	return nil, nil
+}
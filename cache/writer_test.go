@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"sync"
+	"testing"
+	"time"
+)
+
+type writerMock struct {
+	mu       sync.Mutex
+	written  map[string]int
+	deleted  []string
+	failures int
+}
+
+func newWriterMock(failures int) *writerMock {
+	return &writerMock{written: map[string]int{}, failures: failures}
+}
+
+func (w *writerMock) Write(_ context.Context, k string, v int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		return fmt.Errorf("error")
+	}
+	w.written[k] = v
+	return nil
+}
+
+func (w *writerMock) Delete(_ context.Context, k string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		return fmt.Errorf("error")
+	}
+	w.deleted = append(w.deleted, k)
+	return nil
+}
+
+func TestRecordCache_SetWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		writer     Writer[string, int]
+		wantErr    bool
+		wantCached bool
+	}{
+		{
+			name:       "write through stores in source and cache",
+			writer:     newWriterMock(0),
+			wantCached: true,
+		},
+		{
+			name:       "failed write leaves cache untouched",
+			writer:     newWriterMock(1),
+			wantErr:    true,
+			wantCached: false,
+		},
+		{
+			name:    "no writer",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+			r := NewRecordCache[string, int](c)
+			if tt.writer != nil {
+				r.SetWriter(tt.writer)
+			}
+			err := r.Set(context.Background(), "k", 1)
+			if (err != nil) != tt.wantErr || (tt.writer == nil && !errors.Is(err, ErrNoWriter)) {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := c.Has(context.Background(), "k"); got != tt.wantCached {
+				t.Errorf("cached = %v, want %v", got, tt.wantCached)
+			}
+			if !tt.wantCached {
+				return
+			}
+			if err = r.Delete(context.Background(), "k"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if c.Has(context.Background(), "k") {
+				t.Errorf("record still cached after Delete()")
+			}
+			w := tt.writer.(*writerMock)
+			if w.written["k"] != 1 || len(w.deleted) != 1 {
+				t.Errorf("writer got written = %v, deleted = %v", w.written, w.deleted)
+			}
+		})
+	}
+}
+
+func TestRecordCache_SetWriteBehind(t *testing.T) {
+	w := newWriterMock(0)
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	r := NewRecordCache[string, int](c).SetWriteBehind(w, WriteBehindConfig{FlushInterval: time.Hour})
+
+	for i := 1; i <= 3; i++ {
+		if err := r.Set(context.Background(), "k", i); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	if err := r.Delete(context.Background(), "gone"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if v, _ := c.Get(context.Background(), "k"); v.V != 3 {
+		t.Errorf("cached value = %v, want 3", v.V)
+	}
+	r.Close()
+	if len(w.written) != 1 || w.written["k"] != 3 {
+		t.Errorf("written = %v, want coalesced k: 3", w.written)
+	}
+	if len(w.deleted) != 1 || w.deleted[0] != "gone" {
+		t.Errorf("deleted = %v, want [gone]", w.deleted)
+	}
+}
+
+func TestRecordCache_writeBehindRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		wantErr   bool
+		wantWrite bool
+	}{
+		{
+			name:      "succeeds after retry",
+			failures:  1,
+			wantWrite: true,
+		},
+		{
+			name:     "dropped after exhausting retries",
+			failures: 10,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWriterMock(tt.failures)
+			var hooked error
+			r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+				AddErrorHook(func(_ context.Context, err error) { hooked = err }).
+				SetWriteBehind(w, WriteBehindConfig{FlushInterval: time.Hour, MaxRetries: 2, RetryBackoff: time.Millisecond})
+			if err := r.Set(context.Background(), "k", 1); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			r.Close()
+			var wbErr *WriteBehindError[string, int]
+			if errors.As(hooked, &wbErr) != tt.wantErr {
+				t.Errorf("error hook got = %v, wantErr %v", hooked, tt.wantErr)
+			}
+			if _, ok := w.written["k"]; ok != tt.wantWrite {
+				t.Errorf("written = %v, wantWrite %v", w.written, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestRecordCache_SetWriter_inFlightFetch(t *testing.T) {
+	tests := []struct {
+		name      string
+		write     func(r *RecordCache[string, int]) error
+		wantValue int
+		wantHas   bool
+	}{
+		{
+			name:      "set",
+			write:     func(r *RecordCache[string, int]) error { return r.Set(context.Background(), "k", 2) },
+			wantValue: 2,
+			wantHas:   true,
+		},
+		{
+			name:  "delete",
+			write: func(r *RecordCache[string, int]) error { return r.Delete(context.Background(), "k") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started, release := make(chan struct{}), make(chan struct{})
+			c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+			r := NewRecordCache[string, int](c).
+				SetWriter(newWriterMock(0)).
+				SetOnDemandFetcher(OnDemandFetcherFunc[string, int](func(context.Context, string) (int, error) {
+					close(started)
+					<-release
+					return 1, nil
+				}), time.Minute)
+			defer r.Close()
+
+			got := make(chan int)
+			go func() {
+				v, _ := r.Get(context.Background(), "k")
+				got <- v
+			}()
+			<-started
+			if err := tt.write(r); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			close(release)
+			if v := <-got; v != 1 {
+				t.Errorf("Get() started before the write = %v, want 1", v)
+			}
+			item, ok := c.Get(context.Background(), "k")
+			if ok != tt.wantHas || item.V != tt.wantValue {
+				t.Errorf("cached = %v, %v, want %v, %v", item.V, ok, tt.wantValue, tt.wantHas)
+			}
+		})
+	}
+}
+
+func TestRecordCache_SetWriteBehind_pendingReads(t *testing.T) {
+	tests := []struct {
+		name    string
+		write   func(r *RecordCache[string, int]) error
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "pending delete is not fetched again",
+			write:   func(r *RecordCache[string, int]) error { return r.Delete(context.Background(), "k") },
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "pending value is served after eviction",
+			write: func(r *RecordCache[string, int]) error {
+				if err := r.Set(context.Background(), "k", 2); err != nil {
+					return err
+				}
+				r.deleteRecord(context.Background(), "k")
+				return nil
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+				SetWriteBehind(newWriterMock(0), WriteBehindConfig{FlushInterval: time.Hour}).
+				SetOnDemandFetcher(OnDemandFetcherFunc[string, int](func(context.Context, string) (int, error) {
+					fetches++
+					return 1, nil
+				}), time.Minute)
+			defer r.Close()
+
+			if err := tt.write(r); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			got, err := r.Get(context.Background(), "k")
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Get() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+			if fetches != 0 {
+				t.Errorf("fetches = %d, want 0", fetches)
+			}
+		})
+	}
+}
+
+func TestRecordCache_SetWriteBehind_afterClose(t *testing.T) {
+	w := newWriterMock(0)
+	c := driver.NewMemoryCache[string, RecordCacheItem[int]]()
+	r := NewRecordCache[string, int](c).SetWriteBehind(w, WriteBehindConfig{FlushInterval: time.Hour})
+	r.Close()
+
+	if err := r.Set(context.Background(), "k", 1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Set() error = %v, want %v", err, ErrClosed)
+	}
+	if err := r.Delete(context.Background(), "k"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Delete() error = %v, want %v", err, ErrClosed)
+	}
+	if c.Has(context.Background(), "k") {
+		t.Errorf("record cached after a rejected Set()")
+	}
+}
+
+func TestRecordCache_SetWriteBehind_closeTimeout(t *testing.T) {
+	var hooked error
+	r := NewRecordCache[string, int](driver.NewMemoryCache[string, RecordCacheItem[int]]()).
+		AddErrorHook(func(_ context.Context, err error) { hooked = err }).
+		SetWriteBehind(newWriterMock(100), WriteBehindConfig{
+			FlushInterval: time.Hour,
+			RetryBackoff:  time.Hour,
+			CloseTimeout:  10 * time.Millisecond,
+		})
+	if err := r.Set(context.Background(), "k", 1); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		r.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close() did not return after the close timeout")
+	}
+	var wbErr *WriteBehindError[string, int]
+	if !errors.As(hooked, &wbErr) || !errors.Is(hooked, context.Canceled) || len(wbErr.Ops) != 1 {
+		t.Errorf("error hook got = %v, want dropped write", hooked)
+	}
+}
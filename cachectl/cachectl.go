@@ -0,0 +1,188 @@
+// Package cachectl inspects and manages the caches stored in Redis by driver.RedisCache, decoding their gob encoded
+// keys and values.
+package cachectl
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"github.com/redis/go-redis/v9"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Cache is a hash written by a driver.RedisCache.
+type Cache struct {
+	Key     string
+	Entries int64
+}
+
+// Entry is a decoded record of a cache. Key is the hex encoded hash field when it could not be decoded.
+type Entry struct {
+	Field string
+	Key   any
+	Item  Item
+	Err   error
+}
+
+// Age returns how long ago the record was fetched.
+func (e Entry) Age(now time.Time) time.Duration {
+	return now.Sub(e.Item.T)
+}
+
+// Client reads and modifies caches through a Redis client, decoding hashes with the decoder registered for their key
+// or with the default decoder.
+type Client struct {
+	rdb *redis.Client
+	def Decoder
+}
+
+func NewClient(rdb *redis.Client) *Client {
+	return &Client{rdb: rdb, def: GobDecoder()}
+}
+
+// SetDefaultDecoder sets the decoder used for caches without a registered decoder. Defaults to GobDecoder.
+func (c *Client) SetDefaultDecoder(d Decoder) *Client {
+	c.def = d
+	return c
+}
+
+// List returns the caches matching pattern, as defined by the Redis SCAN command, sorted by key. The metadata hashes
+// written alongside caches are skipped.
+func (c *Client) List(ctx context.Context, pattern string) ([]Cache, error) {
+	var keys []string
+	iter := c.rdb.ScanType(ctx, 0, pattern, 100, "hash").Iterator()
+	for iter.Next(ctx) {
+		if !strings.HasSuffix(iter.Val(), ":meta") {
+			keys = append(keys, iter.Val())
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+	caches := make([]Cache, 0, len(keys))
+	for _, key := range keys {
+		n, err := c.rdb.HLen(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		caches = append(caches, Cache{Key: key, Entries: n})
+	}
+	return caches, nil
+}
+
+// Dump returns every record of the cache at hashKey. Records which cannot be decoded are returned with Err set.
+func (c *Client) Dump(ctx context.Context, hashKey string) ([]Entry, error) {
+	all, err := c.rdb.HGetAll(ctx, hashKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	d := decoderFor(hashKey, c.def)
+	entries := make([]Entry, 0, len(all))
+	for field, value := range all {
+		entries = append(entries, decodeEntry(d, field, value))
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return entries, nil
+}
+
+// Get returns the record of the cache at hashKey for key, given as a string, as JSON for other key types, or as the
+// hex encoded hash field prefixed with "0x".
+func (c *Client) Get(ctx context.Context, hashKey string, key string) (Entry, bool, error) {
+	d := decoderFor(hashKey, c.def)
+	fields, err := c.fields(d, key)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	for _, field := range fields {
+		value, err := c.rdb.HGet(ctx, hashKey, field).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return Entry{}, false, err
+		}
+		return decodeEntry(d, field, value), true, nil
+	}
+	return Entry{}, false, nil
+}
+
+// Delete removes the records of the cache at hashKey for keys, given as for Get, returning the number removed.
+func (c *Client) Delete(ctx context.Context, hashKey string, keys ...string) (int64, error) {
+	d := decoderFor(hashKey, c.def)
+	var fields []string
+	for _, key := range keys {
+		f, err := c.fields(d, key)
+		if err != nil {
+			return 0, err
+		}
+		fields = append(fields, f...)
+	}
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	return c.rdb.HDel(ctx, hashKey, fields...).Result()
+}
+
+// Purge removes the records of the cache at hashKey fetched more than olderThan ago, returning the number removed.
+// Records which cannot be decoded are kept. olderThan must be positive; use PurgeAll to remove every record.
+func (c *Client) Purge(ctx context.Context, hashKey string, olderThan time.Duration) (int64, error) {
+	if olderThan <= 0 {
+		return 0, fmt.Errorf("purge age must be positive, got %s", olderThan)
+	}
+	entries, err := c.Dump(ctx, hashKey)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	var fields []string
+	for _, e := range entries {
+		if e.Err == nil && e.Age(now) > olderThan {
+			fields = append(fields, e.Field)
+		}
+	}
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	return c.rdb.HDel(ctx, hashKey, fields...).Result()
+}
+
+// PurgeAll removes every record of the cache at hashKey, returning the number removed.
+func (c *Client) PurgeAll(ctx context.Context, hashKey string) (int64, error) {
+	n, err := c.rdb.HLen(ctx, hashKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	return n, c.rdb.Del(ctx, hashKey).Err()
+}
+
+func (c *Client) fields(d Decoder, key string) ([]string, error) {
+	if raw, ok := strings.CutPrefix(key, "0x"); ok {
+		if b, err := hex.DecodeString(raw); err == nil {
+			return []string{string(b)}, nil
+		}
+	}
+	return d.EncodeKey(key)
+}
+
+func decodeEntry(d Decoder, field string, value string) Entry {
+	e := Entry{Field: field}
+	k, err := d.DecodeKey([]byte(field))
+	if err != nil {
+		e.Key = "0x" + hex.EncodeToString([]byte(field))
+	} else {
+		e.Key = k
+	}
+	b, err := driver.Decompress([]byte(value))
+	if err != nil {
+		e.Err = err
+		return e
+	}
+	e.Item, e.Err = d.DecodeItem(b)
+	return e
+}
@@ -0,0 +1,108 @@
+package cachectl
+
+import (
+	"bytes"
+	"context"
+	"github.com/ellogroup/ello-golang-cache/cache"
+	"github.com/ellogroup/ello-golang-cache/driver"
+	"github.com/go-redis/redismock/v9"
+	"github.com/klauspost/compress/s2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Get(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	item := mustGob(t, cache.RecordCacheItem[string]{V: "one", T: time.Now()})
+	mock.ExpectHGet("numbers", mustGob(t, "1")).RedisNil()
+	mock.ExpectHGet("numbers", mustGob(t, int64(1))).SetVal(item)
+
+	e, ok, err := NewClient(r).Get(context.Background(), "numbers", "1")
+	if err != nil || !ok {
+		t.Fatalf("Get() = %v, %v, want found", ok, err)
+	}
+	if e.Key != int64(1) || e.Item.Value != "one" {
+		t.Errorf("Get() = %+v, want key 1 and value one", e)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestClient_GetCompressed(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	item := mustGob(t, cache.RecordCacheItem[string]{V: "one", T: time.Now()})
+	compressed := append([]byte{0xCE, byte(driver.Snappy)}, s2.EncodeSnappy(nil, []byte(item))...)
+	mock.ExpectHGet("numbers", mustGob(t, "1")).SetVal(string(compressed))
+
+	e, ok, err := NewClient(r).Get(context.Background(), "numbers", "1")
+	if err != nil || !ok {
+		t.Fatalf("Get() = %v, %v, want found", ok, err)
+	}
+	if e.Err != nil || e.Item.Value != "one" {
+		t.Errorf("Get() = %+v, want value one", e)
+	}
+}
+
+func TestClient_Purge(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	fresh := mustGob(t, cache.RecordCacheItem[string]{V: "a", T: time.Now()})
+	old := mustGob(t, cache.RecordCacheItem[string]{V: "b", T: time.Now().Add(-time.Hour)})
+	mock.ExpectHGetAll("letters").SetVal(map[string]string{mustGob(t, "a"): fresh, mustGob(t, "b"): old})
+	mock.ExpectHDel("letters", mustGob(t, "b")).SetVal(1)
+
+	mock.ExpectHLen("letters").SetVal(1)
+	mock.ExpectDel("letters").SetVal(1)
+
+	c := NewClient(r)
+	n, err := c.Purge(context.Background(), "letters", time.Minute)
+	if err != nil || n != 1 {
+		t.Errorf("Purge() = %v, %v, want 1", n, err)
+	}
+	if _, err = c.Purge(context.Background(), "letters", 0); err == nil {
+		t.Errorf("Purge() without an age error = nil, want error")
+	}
+	if n, err = c.PurgeAll(context.Background(), "letters"); err != nil || n != 1 {
+		t.Errorf("PurgeAll() = %v, %v, want 1", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func Test_run(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectScanType(0, "*", 100, "hash").SetVal([]string{"letters", "letters:meta"}, 0)
+	mock.ExpectHLen("letters").SetVal(2)
+	old := mustGob(t, cache.RecordCacheItem[string]{V: "b", T: time.Now().Add(-time.Hour)})
+	mock.ExpectHGetAll("letters").SetVal(map[string]string{mustGob(t, "b"): old})
+	mock.ExpectHDel("letters", mustGob(t, "a")).SetVal(1)
+	c := NewClient(r)
+
+	tests := []struct {
+		name    string
+		args    []string
+		ttl     time.Duration
+		want    string
+		wantErr bool
+	}{
+		{name: "list", args: []string{"list"}, ttl: 30 * time.Minute, want: "letters  2"},
+		{name: "dump", args: []string{"dump", "letters"}, ttl: 30 * time.Minute, want: "b    1h0m0s  true"},
+		{name: "del", args: []string{"del", "letters", "a"}, ttl: 30 * time.Minute, want: "deleted 1 records"},
+		{name: "invalid", args: []string{"get", "letters"}, ttl: 30 * time.Minute, wantErr: true},
+		{name: "purge without -ttl or -all", args: []string{"purge", "letters"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run(context.Background(), c, tt.args, tt.ttl, false, &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("run() output = %q, want it to contain %q", out.String(), tt.want)
+			}
+		})
+	}
+}
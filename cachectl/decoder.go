@@ -0,0 +1,250 @@
+package cachectl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/cache"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Item is a decoded cache.RecordCacheItem. Value is nil when the value could not be decoded.
+type Item struct {
+	Value  any
+	T      time.Time
+	Async  bool
+	Source string
+	ETag   string
+	Tags   []string
+}
+
+// Decoder decodes the fields and values of the hash written by a driver.RedisCache.
+type Decoder interface {
+	// DecodeKey decodes a hash field into the cache key.
+	DecodeKey(b []byte) (any, error)
+	// EncodeKey returns the hash fields a key given on the command line may be stored as.
+	EncodeKey(s string) ([]string, error)
+	// DecodeItem decodes a hash value into the cached item.
+	DecodeItem(b []byte) (Item, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registration
+)
+
+type registration struct {
+	pattern string
+	d       Decoder
+}
+
+// Register decodes the caches whose hash key matches pattern, as defined by path.Match, as gob encoded keys of type K
+// and RecordCacheItem[V] values. Build your own cachectl registering your types to inspect caches of custom types.
+func Register[K comparable, V any](pattern string) {
+	RegisterDecoder(pattern, typedDecoder[K, V]{})
+}
+
+// RegisterDecoder decodes the caches whose hash key matches pattern, as defined by path.Match, with d.
+func RegisterDecoder(pattern string, d Decoder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, registration{pattern: pattern, d: d})
+}
+
+// decoderFor returns the first decoder registered for a pattern matching hashKey, or def.
+func decoderFor(hashKey string, def Decoder) Decoder {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, reg := range registry {
+		if ok, _ := path.Match(reg.pattern, hashKey); ok {
+			return reg.d
+		}
+	}
+	return def
+}
+
+type typedDecoder[K comparable, V any] struct{}
+
+func (typedDecoder[K, V]) DecodeKey(b []byte) (any, error) {
+	var k K
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&k)
+	return k, err
+}
+
+func (typedDecoder[K, V]) EncodeKey(s string) ([]string, error) {
+	k, err := cache.ParseKey[K](s)
+	if err != nil {
+		return nil, err
+	}
+	b, err := gobEncode(k)
+	if err != nil {
+		return nil, err
+	}
+	return []string{b}, nil
+}
+
+func (typedDecoder[K, V]) DecodeItem(b []byte) (Item, error) {
+	var item cache.RecordCacheItem[V]
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&item); err != nil {
+		return Item{}, err
+	}
+	return Item{Value: item.V, T: item.T, Async: item.Async, Source: item.Source, ETag: item.ETag, Tags: item.Tags}, nil
+}
+
+// GobDecoder returns a decoder for gob encoded caches of unknown types. Keys and values of basic types are decoded,
+// while the metadata of items is always decoded, leaving the Value of other types nil.
+func GobDecoder() Decoder {
+	return gobDecoder{}
+}
+
+type gobDecoder struct{}
+
+// itemMeta holds the fields of a RecordCacheItem which do not depend on its type; gob ignores the V field.
+type itemMeta struct {
+	T      time.Time
+	Async  bool
+	Source string
+	ETag   string
+	Tags   []string
+}
+
+func (gobDecoder) DecodeKey(b []byte) (any, error) {
+	for _, k := range []any{new(string), new(int64), new(uint64), new(float64), new(bool)} {
+		if err := gob.NewDecoder(bytes.NewReader(b)).Decode(k); err == nil {
+			return deref(k), nil
+		}
+	}
+	return nil, fmt.Errorf("key of unknown type, register its type to decode it")
+}
+
+func (gobDecoder) EncodeKey(s string) ([]string, error) {
+	candidates := []any{s}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		candidates = append(candidates, i)
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		candidates = append(candidates, u)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		candidates = append(candidates, f)
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		candidates = append(candidates, b)
+	}
+	fields := make([]string, 0, len(candidates))
+	for _, k := range candidates {
+		b, err := gobEncode(k)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, b)
+	}
+	return fields, nil
+}
+
+func (gobDecoder) DecodeItem(b []byte) (Item, error) {
+	var meta itemMeta
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&meta); err != nil {
+		return Item{}, err
+	}
+	item := Item{T: meta.T, Async: meta.Async, Source: meta.Source, ETag: meta.ETag, Tags: meta.Tags}
+	for _, v := range []any{&struct{ V string }{}, &struct{ V int64 }{}, &struct{ V float64 }{}, &struct{ V bool }{}} {
+		if err := gob.NewDecoder(bytes.NewReader(b)).Decode(v); err == nil {
+			item.Value = valueOf(v)
+			break
+		}
+	}
+	return item, nil
+}
+
+// JSONDecoder returns a decoder for caches whose keys and values are encoded as JSON.
+func JSONDecoder() Decoder {
+	return jsonDecoder{}
+}
+
+type jsonDecoder struct{}
+
+func (jsonDecoder) DecodeKey(b []byte) (any, error) {
+	var k any
+	if err := json.Unmarshal(b, &k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (jsonDecoder) EncodeKey(s string) ([]string, error) {
+	quoted, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	if json.Valid([]byte(s)) {
+		return []string{s, string(quoted)}, nil
+	}
+	return []string{string(quoted)}, nil
+}
+
+func (jsonDecoder) DecodeItem(b []byte) (Item, error) {
+	var item cache.RecordCacheItem[any]
+	if err := json.Unmarshal(b, &item); err != nil {
+		return Item{}, err
+	}
+	return Item{Value: item.V, T: item.T, Async: item.Async, Source: item.Source, ETag: item.ETag, Tags: item.Tags}, nil
+}
+
+// TextKeys returns a decoder for caches whose keys are encoded with driver.TextKeyCodec, decoding values with d. Keys
+// are shown and given on the command line as the text stored in the hash.
+func TextKeys(d Decoder) Decoder {
+	return textKeyDecoder{d}
+}
+
+type textKeyDecoder struct {
+	Decoder
+}
+
+func (textKeyDecoder) DecodeKey(b []byte) (any, error) {
+	return string(b), nil
+}
+
+func (textKeyDecoder) EncodeKey(s string) ([]string, error) {
+	return []string{s}, nil
+}
+
+func gobEncode(v any) (string, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(v)
+	return b.String(), err
+}
+
+func deref(p any) any {
+	switch p := p.(type) {
+	case *string:
+		return *p
+	case *int64:
+		return *p
+	case *uint64:
+		return *p
+	case *float64:
+		return *p
+	case *bool:
+		return *p
+	}
+	return p
+}
+
+func valueOf(p any) any {
+	switch p := p.(type) {
+	case *struct{ V string }:
+		return p.V
+	case *struct{ V int64 }:
+		return p.V
+	case *struct{ V float64 }:
+		return p.V
+	case *struct{ V bool }:
+		return p.V
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cachectl
+
+import (
+	"github.com/ellogroup/ello-golang-cache/cache"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testKey struct{ Id string }
+
+type testValue struct{ Name string }
+
+func mustGob(t *testing.T, v any) string {
+	t.Helper()
+	b, err := gobEncode(v)
+	if err != nil {
+		t.Fatalf("gobEncode() error = %v", err)
+	}
+	return b
+}
+
+func TestGobDecoder(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		key     any
+		item    any
+		wantKey any
+		want    Item
+	}{
+		{
+			name:    "basic types",
+			key:     42,
+			item:    cache.RecordCacheItem[string]{V: "a", T: at, Source: "db"},
+			wantKey: int64(42),
+			want:    Item{Value: "a", T: at, Source: "db"},
+		},
+		{
+			name:    "unknown value type keeps metadata",
+			key:     "a",
+			item:    cache.RecordCacheItem[testValue]{V: testValue{Name: "a"}, T: at, Async: true, Tags: []string{"t"}},
+			wantKey: "a",
+			want:    Item{T: at, Async: true, Tags: []string{"t"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := GobDecoder()
+			k, err := d.DecodeKey([]byte(mustGob(t, tt.key)))
+			if err != nil || k != tt.wantKey {
+				t.Errorf("DecodeKey() = %v, %v, want %v", k, err, tt.wantKey)
+			}
+			item, err := d.DecodeItem([]byte(mustGob(t, tt.item)))
+			if err != nil || !reflect.DeepEqual(item, tt.want) {
+				t.Errorf("DecodeItem() = %+v, %v, want %+v", item, err, tt.want)
+			}
+		})
+	}
+	if _, err := GobDecoder().DecodeKey([]byte(mustGob(t, testKey{Id: "a"}))); err == nil {
+		t.Errorf("DecodeKey() of unknown type error = nil, want error")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register[testKey, testValue]("users:*")
+	d := decoderFor("users:v1", GobDecoder())
+	fields, err := d.EncodeKey(`{"Id":"a"}`)
+	if err != nil || !reflect.DeepEqual(fields, []string{mustGob(t, testKey{Id: "a"})}) {
+		t.Fatalf("EncodeKey() = %q, %v", fields, err)
+	}
+	k, err := d.DecodeKey([]byte(fields[0]))
+	if err != nil || k != (testKey{Id: "a"}) {
+		t.Errorf("DecodeKey() = %v, %v, want {a}", k, err)
+	}
+	item, err := d.DecodeItem([]byte(mustGob(t, cache.RecordCacheItem[testValue]{V: testValue{Name: "b"}})))
+	if err != nil || item.Value != (testValue{Name: "b"}) {
+		t.Errorf("DecodeItem() = %+v, %v, want value {b}", item, err)
+	}
+	if _, ok := decoderFor("orders", GobDecoder()).(gobDecoder); !ok {
+		t.Errorf("decoderFor() of unregistered cache did not return the default decoder")
+	}
+}
+
+func TestJSONDecoder(t *testing.T) {
+	d := JSONDecoder()
+	k, err := d.DecodeKey([]byte(`{"Id":"a"}`))
+	if err != nil || !reflect.DeepEqual(k, map[string]any{"Id": "a"}) {
+		t.Errorf("DecodeKey() = %v, %v", k, err)
+	}
+	item, err := d.DecodeItem([]byte(`{"V":{"Name":"b"},"T":"2024-01-01T00:00:00Z","Source":"db"}`))
+	want := Item{Value: map[string]any{"Name": "b"}, T: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Source: "db"}
+	if err != nil || !reflect.DeepEqual(item, want) {
+		t.Errorf("DecodeItem() = %+v, %v, want %+v", item, err, want)
+	}
+	if fields, _ := d.EncodeKey("a"); !reflect.DeepEqual(fields, []string{`"a"`}) {
+		t.Errorf("EncodeKey() = %q, want [\"a\"]", fields)
+	}
+}
+
+func TestTextKeys(t *testing.T) {
+	d := TextKeys(JSONDecoder())
+	if k, err := d.DecodeKey([]byte("user:42")); err != nil || k != "user:42" {
+		t.Errorf("DecodeKey() = %v, %v, want user:42", k, err)
+	}
+	if fields, _ := d.EncodeKey("42"); !reflect.DeepEqual(fields, []string{"42"}) {
+		t.Errorf("EncodeKey() = %q, want [42]", fields)
+	}
+	item, err := d.DecodeItem([]byte(`{"V":"b","T":"2024-01-01T00:00:00Z"}`))
+	if err != nil || item.Value != "b" {
+		t.Errorf("DecodeItem() = %+v, %v", item, err)
+	}
+}
@@ -0,0 +1,159 @@
+package cachectl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: cachectl [flags] <command> [args]
+
+Commands:
+  list [pattern]            list caches, optionally matching a SCAN pattern
+  dump <cache>              show every record of a cache
+  get <cache> <key>         show the record for a key
+  del <cache> <key>...      delete the records for keys
+  purge <cache>             delete records older than -ttl, or every record with -all
+
+Keys are given as strings, as JSON for other key types, or as a hex encoded hash field prefixed with 0x.
+
+The Redis password is read from the REDIS_PASSWORD environment variable.
+
+Flags:
+`
+
+// passwordEnv is the environment variable the Redis password is read from. It is not a flag, so that it does not
+// appear in the process list or the shell history.
+const passwordEnv = "REDIS_PASSWORD"
+
+// Run runs the cachectl command line with args, excluding the program name, writing its output to out. Programs
+// registering their own types with Register can call it from main.
+func Run(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("cachectl", flag.ContinueOnError)
+	fs.SetOutput(out)
+	addr := fs.String("addr", "localhost:6379", "Redis address")
+	db := fs.Int("db", 0, "Redis database")
+	ttl := fs.Duration("ttl", 0, "ttl of the cache, used to show stale records and by purge")
+	all := fs.Bool("all", false, "purge every record of the cache, regardless of -ttl")
+	format := fs.String("format", "gob", "encoding of caches without a registered decoder: gob or json")
+	keys := fs.String("keys", "", "encoding of keys when it differs from -format: text")
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(out, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var d Decoder
+	switch *format {
+	case "gob":
+		d = GobDecoder()
+	case "json":
+		d = JSONDecoder()
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	switch *keys {
+	case "":
+	case "text":
+		d = TextKeys(d)
+	default:
+		return fmt.Errorf("unknown key encoding %q", *keys)
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: *addr, Password: os.Getenv(passwordEnv), DB: *db})
+	defer rdb.Close()
+	return run(ctx, NewClient(rdb).SetDefaultDecoder(d), fs.Args(), *ttl, *all, out)
+}
+
+var errUsage = errors.New("invalid arguments, see cachectl -h")
+
+var errPurgeAge = errors.New("purge needs -ttl, or -all to delete every record")
+
+func run(ctx context.Context, c *Client, args []string, ttl time.Duration, all bool, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+	switch cmd, args := args[0], args[1:]; {
+	case cmd == "list" && len(args) <= 1:
+		pattern := "*"
+		if len(args) == 1 {
+			pattern = args[0]
+		}
+		caches, err := c.List(ctx, pattern)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(w, "CACHE\tENTRIES")
+		for _, cache := range caches {
+			_, _ = fmt.Fprintf(w, "%s\t%d\n", cache.Key, cache.Entries)
+		}
+	case cmd == "dump" && len(args) == 1:
+		entries, err := c.Dump(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		printEntries(w, entries, ttl)
+	case cmd == "get" && len(args) == 2:
+		e, ok, err := c.Get(ctx, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("key %s not found in %s", args[1], args[0])
+		}
+		printEntries(w, []Entry{e}, ttl)
+	case cmd == "del" && len(args) >= 2:
+		n, err := c.Delete(ctx, args[0], args[1:]...)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "deleted %d records\n", n)
+	case cmd == "purge" && len(args) == 1:
+		var n int64
+		var err error
+		switch {
+		case all:
+			n, err = c.PurgeAll(ctx, args[0])
+		case ttl > 0:
+			n, err = c.Purge(ctx, args[0], ttl)
+		default:
+			return errPurgeAge
+		}
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "purged %d records\n", n)
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func printEntries(w io.Writer, entries []Entry, ttl time.Duration) {
+	now := time.Now()
+	_, _ = fmt.Fprintln(w, "KEY\tAGE\tSTALE\tASYNC\tSOURCE\tTAGS\tVALUE")
+	for _, e := range entries {
+		if e.Err != nil {
+			_, _ = fmt.Fprintf(w, "%v\t-\t-\t-\t-\t-\t<%v>\n", e.Key, e.Err)
+			continue
+		}
+		stale := "-"
+		if ttl > 0 {
+			stale = fmt.Sprint(e.Age(now) > ttl)
+		}
+		value := any("<unknown type>")
+		if e.Item.Value != nil {
+			value = e.Item.Value
+		}
+		_, _ = fmt.Fprintf(w, "%v\t%s\t%s\t%t\t%s\t%v\t%v\n", e.Key, e.Age(now).Truncate(time.Second), stale,
+			e.Item.Async, e.Item.Source, e.Item.Tags, value)
+	}
+}
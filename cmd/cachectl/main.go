@@ -0,0 +1,26 @@
+// Command cachectl inspects and manages the caches stored in Redis by driver.RedisCache. Run cachectl -h for usage.
+//
+// Keys and values of basic types are decoded out of the box. To decode caches of custom types, build your own
+// command registering them with cachectl.Register before calling cachectl.Run.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ellogroup/ello-golang-cache/cachectl"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := cachectl.Run(ctx, os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,87 @@
+package driver
+
+import "context"
+
+// FromV1 adapts a Cache to a CacheV2. As a Cache cannot tell a miss from a failure, Get returns ErrNotFound whenever
+// the item is not returned, and other operations returning false fail with a *BackendError.
+func FromV1[K comparable, V any](c Cache[K, V]) CacheV2[K, V] {
+	return v1Adapter[K, V]{c: c}
+}
+
+// ToV1 adapts a CacheV2 to a Cache, reporting every error as false.
+func ToV1[K comparable, V any](c CacheV2[K, V]) Cache[K, V] {
+	return v2Adapter[K, V]{c: c}
+}
+
+type v1Adapter[K comparable, V any] struct {
+	c Cache[K, V]
+}
+
+func (a v1Adapter[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	return a.c.Has(ctx, key), nil
+}
+
+func (a v1Adapter[K, V]) Get(ctx context.Context, key K) (V, error) {
+	v, ok := a.c.Get(ctx, key)
+	if !ok {
+		return v, ErrNotFound
+	}
+	return v, nil
+}
+
+func (a v1Adapter[K, V]) All(ctx context.Context) (map[K]V, error) {
+	return a.c.All(ctx), nil
+}
+
+func (a v1Adapter[K, V]) Set(ctx context.Context, key K, value V) error {
+	return v1Err("set", a.c.Set(ctx, key, value))
+}
+
+func (a v1Adapter[K, V]) Delete(ctx context.Context, key K) error {
+	return v1Err("delete", a.c.Delete(ctx, key))
+}
+
+func (a v1Adapter[K, V]) Clear(ctx context.Context) error {
+	return v1Err("clear", a.c.Clear(ctx))
+}
+
+func v1Err(op string, ok bool) error {
+	if !ok {
+		return &BackendError{Op: op, Err: errV1Failed}
+	}
+	return nil
+}
+
+type v2Adapter[K comparable, V any] struct {
+	c CacheV2[K, V]
+}
+
+func (a v2Adapter[K, V]) Has(ctx context.Context, key K) bool {
+	ok, err := a.c.Has(ctx, key)
+	return ok && err == nil
+}
+
+func (a v2Adapter[K, V]) Get(ctx context.Context, key K) (V, bool) {
+	v, err := a.c.Get(ctx, key)
+	return v, err == nil
+}
+
+func (a v2Adapter[K, V]) All(ctx context.Context) map[K]V {
+	all, err := a.c.All(ctx)
+	if err != nil {
+		return map[K]V{}
+	}
+	return all
+}
+
+func (a v2Adapter[K, V]) Set(ctx context.Context, key K, value V) bool {
+	return a.c.Set(ctx, key, value) == nil
+}
+
+func (a v2Adapter[K, V]) Delete(ctx context.Context, key K) bool {
+	return a.c.Delete(ctx, key) == nil
+}
+
+func (a v2Adapter[K, V]) Clear(ctx context.Context) bool {
+	return a.c.Clear(ctx) == nil
+}
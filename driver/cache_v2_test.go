@@ -0,0 +1,82 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type failingCache struct {
+	Cache[int, string]
+}
+
+func (failingCache) Set(context.Context, int, string) bool {
+	return false
+}
+
+func TestFromV1(t *testing.T) {
+	ctx := context.Background()
+	c := FromV1[int, string](NewMemoryCache[int, string]())
+	if err := c.Set(ctx, 1, "one"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	if v, err := c.Get(ctx, 1); err != nil || v != "one" {
+		t.Errorf("Get() = %v, %v, want one, nil", v, err)
+	}
+	if _, err := c.Get(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of missing key error = %v, want ErrNotFound", err)
+	}
+	if all, err := c.All(ctx); err != nil || !reflect.DeepEqual(all, map[int]string{1: "one"}) {
+		t.Errorf("All() = %v, %v", all, err)
+	}
+	var backendErr *BackendError
+	failing := FromV1[int, string](failingCache{NewMemoryCache[int, string]()})
+	if err := failing.Set(ctx, 1, "one"); !errors.As(err, &backendErr) || backendErr.Op != "set" {
+		t.Errorf("Set() error = %v, want set *BackendError", err)
+	}
+}
+
+type unavailableCache[K comparable, V any] struct{}
+
+func (unavailableCache[K, V]) Has(context.Context, K) (bool, error) {
+	return false, &BackendError{Op: "has", Err: errors.New("refused")}
+}
+
+func (unavailableCache[K, V]) Get(context.Context, K) (V, error) {
+	return *new(V), &BackendError{Op: "get", Err: errors.New("refused")}
+}
+
+func (unavailableCache[K, V]) All(context.Context) (map[K]V, error) {
+	return nil, &BackendError{Op: "all", Err: errors.New("refused")}
+}
+
+func (unavailableCache[K, V]) Set(context.Context, K, V) error {
+	return &BackendError{Op: "set", Err: errors.New("refused")}
+}
+
+func (unavailableCache[K, V]) Delete(context.Context, K) error {
+	return &BackendError{Op: "delete", Err: errors.New("refused")}
+}
+
+func (unavailableCache[K, V]) Clear(context.Context) error {
+	return &BackendError{Op: "clear", Err: errors.New("refused")}
+}
+
+func TestToV1(t *testing.T) {
+	ctx := context.Background()
+	c := ToV1[int, string](unavailableCache[int, string]{})
+	if _, ok := c.Get(ctx, 1); ok {
+		t.Errorf("Get() ok = true, want false")
+	}
+	if c.Set(ctx, 1, "one") || c.Has(ctx, 1) || c.Delete(ctx, 1) || c.Clear(ctx) {
+		t.Errorf("failed operations returned true")
+	}
+	if all := c.All(ctx); all == nil || len(all) != 0 {
+		t.Errorf("All() = %v, want empty map", all)
+	}
+	ok := ToV1(FromV1[int, string](NewMemoryCache[int, string]()))
+	if !ok.Set(ctx, 1, "one") || !ok.Has(ctx, 1) {
+		t.Errorf("Set() and Has() through both adapters = false, want true")
+	}
+}
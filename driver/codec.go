@@ -0,0 +1,150 @@
+package driver
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"reflect"
+	"strconv"
+)
+
+// Codec encodes keys and values stored by drivers which serialise them, such as RedisCache. Unmarshal is given a
+// pointer to the key or value to decode into.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// GobCodec encodes with encoding/gob. It is the default codec of RedisCache. Interface fields must have their concrete
+// types registered with gob.Register.
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(v)
+	return b.Bytes(), err
+}
+
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// JSONCodec encodes with encoding/json, so that the items can be read by services written in other languages.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtoCodec encodes values implementing proto.Message with the protobuf wire format. Values may be decoded into a
+// message or into a pointer to a message, which is allocated when nil. Use cache.NewItemCodec to store records of
+// messages in a RecordCache.
+type ProtoCodec struct{}
+
+func (ProtoCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("proto codec: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtoCodec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Pointer {
+		return fmt.Errorf("proto codec: cannot decode into %T", v)
+	}
+	m, ok := reflect.New(rv.Elem().Type().Elem()).Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("proto codec: %T does not point to a proto.Message", v)
+	}
+	if err := proto.Unmarshal(data, m); err != nil {
+		return err
+	}
+	rv.Elem().Set(reflect.ValueOf(m))
+	return nil
+}
+
+// TextKeyCodec encodes keys as readable text, so that hash fields can be inspected and deleted by hand. Keys
+// implementing encoding.TextMarshaler are encoded with MarshalText, strings are stored as they are, and integers, floats
+// and bools are formatted with strconv. Other keys implementing fmt.Stringer are encoded with String, and any other key,
+// such as a struct, is encoded as JSON. Stringer keys can only be decoded, for example by RedisCache.All, when they
+// also implement encoding.TextUnmarshaler.
+type TextKeyCodec struct{}
+
+func (TextKeyCodec) Marshal(v any) ([]byte, error) {
+	if m, ok := v.(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return []byte(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(nil, rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.AppendUint(nil, rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.AppendFloat(nil, rv.Float(), 'g', -1, rv.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.AppendBool(nil, rv.Bool()), nil
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return []byte(s.String()), nil
+	}
+	return json.Marshal(v)
+}
+
+func (TextKeyCodec) Unmarshal(data []byte, v any) error {
+	if u, ok := v.(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText(data)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("text key codec: cannot decode into %T", v)
+	}
+	e := rv.Elem()
+	switch e.Kind() {
+	case reflect.String:
+		e.SetString(string(data))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(data), 10, e.Type().Bits())
+		if err == nil {
+			e.SetInt(i)
+		}
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(string(data), 10, e.Type().Bits())
+		if err == nil {
+			e.SetUint(u)
+		}
+		return err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(data), e.Type().Bits())
+		if err == nil {
+			e.SetFloat(f)
+		}
+		return err
+	case reflect.Bool:
+		b, err := strconv.ParseBool(string(data))
+		if err == nil {
+			e.SetBool(b)
+		}
+		return err
+	}
+	if _, ok := e.Interface().(fmt.Stringer); ok {
+		return fmt.Errorf("text key codec: %s implements fmt.Stringer but not encoding.TextUnmarshaler", e.Type())
+	}
+	return json.Unmarshal(data, v)
+}
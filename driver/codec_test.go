@@ -0,0 +1,173 @@
+package driver
+
+import (
+	"context"
+	"github.com/go-redis/redismock/v9"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestCodecs_roundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{name: "gob", codec: GobCodec{}},
+		{name: "json", codec: JSONCodec{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.codec.Marshal(value1())
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var got Value
+			if err := tt.codec.Unmarshal(b, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, value1()) {
+				t.Errorf("Unmarshal() got = %v, want %v", got, value1())
+			}
+		})
+	}
+}
+
+func TestProtoCodec(t *testing.T) {
+	c := ProtoCodec{}
+	b, err := c.Marshal(wrapperspb.String("abc"))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got *wrapperspb.StringValue
+	if err := c.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !proto.Equal(got, wrapperspb.String("abc")) {
+		t.Errorf("Unmarshal() got = %v, want abc", got)
+	}
+	var msg wrapperspb.StringValue
+	if err := c.Unmarshal(b, &msg); err != nil || msg.GetValue() != "abc" {
+		t.Errorf("Unmarshal() into message = %v, %v, want abc", msg.GetValue(), err)
+	}
+	if _, err := c.Marshal("abc"); err == nil {
+		t.Errorf("Marshal() of a string error = nil, want error")
+	}
+	var s string
+	if err := c.Unmarshal(b, &s); err == nil {
+		t.Errorf("Unmarshal() into a string error = nil, want error")
+	}
+}
+
+func TestRedisDriver_codecs(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectHSet("test", `"abc"`, `{"Value":"value_1","Inner":{"InnerValue":""}}`).SetVal(1)
+	mock.ExpectHGet("test", `"abc"`).SetVal(`{"Value":"value_1","Inner":{"InnerValue":""}}`)
+	mock.ExpectHGetAll("test").SetVal(map[string]string{`"abc"`: `{"Value":"value_1","Inner":{"InnerValue":""}}`})
+	c := NewRedisCacheDriver[string, Value]("test", r).SetKeyCodec(JSONCodec{}).SetValueCodec(JSONCodec{})
+	want := Value{Value: "value_1"}
+
+	if !c.Set(context.Background(), "abc", want) {
+		t.Errorf("Set() = false, want true")
+	}
+	if got, ok := c.Get(context.Background(), "abc"); !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, %v, want %v, true", got, ok, want)
+	}
+	if got := c.All(context.Background()); !reflect.DeepEqual(got, map[string]Value{"abc": want}) {
+		t.Errorf("All() = %v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+type textKey struct {
+	Region string
+	Id     int
+}
+
+type stringerKey struct {
+	Id int
+}
+
+func (k stringerKey) String() string {
+	return "id-" + strconv.Itoa(k.Id)
+}
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[l]), nil
+}
+
+func (l *level) UnmarshalText(b []byte) error {
+	*l = map[string]level{"low": 0, "high": 1}[string(b)]
+	return nil
+}
+
+type userId int
+
+func textRoundTrip[K comparable](t *testing.T, key K, want string) {
+	t.Helper()
+	b, err := TextKeyCodec{}.Marshal(key)
+	if err != nil {
+		t.Fatalf("Marshal(%v) error = %v", key, err)
+	}
+	if string(b) != want {
+		t.Errorf("Marshal(%v) = %q, want %q", key, b, want)
+	}
+	var got K
+	if err := (TextKeyCodec{}).Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal(%q) error = %v", b, err)
+	}
+	if got != key {
+		t.Errorf("Unmarshal(%q) = %v, want %v", b, got, key)
+	}
+}
+
+func TestTextKeyCodec(t *testing.T) {
+	textRoundTrip(t, "abc", "abc")
+	textRoundTrip(t, -42, "-42")
+	textRoundTrip(t, uint8(7), "7")
+	textRoundTrip(t, userId(12), "12")
+	textRoundTrip(t, 1.5, "1.5")
+	textRoundTrip(t, true, "true")
+	textRoundTrip(t, level(1), "high")
+	textRoundTrip(t, textKey{Region: "eu", Id: 1}, `{"Region":"eu","Id":1}`)
+
+	b, err := TextKeyCodec{}.Marshal(stringerKey{Id: 3})
+	if err != nil || string(b) != "id-3" {
+		t.Errorf("Marshal() of a Stringer = %q, %v, want id-3", b, err)
+	}
+	var k stringerKey
+	if err := (TextKeyCodec{}).Unmarshal(b, &k); err == nil {
+		t.Errorf("Unmarshal() into a Stringer error = nil, want error")
+	}
+	var i int
+	if err := (TextKeyCodec{}).Unmarshal([]byte("abc"), &i); err == nil {
+		t.Errorf("Unmarshal() of text into an int error = nil, want error")
+	}
+}
+
+func TestRedisDriver_textKeys(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectHSet("test", "42", value1().toGob()).SetVal(1)
+	mock.ExpectHGetAll("test").SetVal(map[string]string{"42": value1().toGob(), "not a number": value1().toGob()})
+	mock.ExpectHDel("test", "42").SetVal(1)
+	c := NewRedisCacheDriver[int, Value]("test", r).SetKeyCodec(TextKeyCodec{})
+
+	if !c.Set(context.Background(), 42, value1()) {
+		t.Errorf("Set() = false, want true")
+	}
+	if got := c.All(context.Background()); !reflect.DeepEqual(got, map[int]Value{42: value1()}) {
+		t.Errorf("All() = %v", got)
+	}
+	if !c.Delete(context.Background(), 42) {
+		t.Errorf("Delete() = false, want true")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"sync"
+)
+
+// Compression is an algorithm compressing the values stored by RedisCache.
+type Compression byte
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+	Snappy
+)
+
+// compressionMagic starts every compressed value, followed by the Compression byte. Neither gob, JSON nor protobuf
+// encodings start with it, so compressed and uncompressed values can be stored in the same cache.
+const compressionMagic = 0xCE
+
+func (c Compression) String() string {
+	switch c {
+	case NoCompression:
+		return "none"
+	case Gzip:
+		return "gzip"
+	case Zstd:
+		return "zstd"
+	case Snappy:
+		return "snappy"
+	}
+	return fmt.Sprintf("Compression(%d)", byte(c))
+}
+
+// zstd encoders and decoders are expensive to create and safe for concurrent use of EncodeAll and DecodeAll, so they
+// are shared.
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil)
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil)
+	})
+)
+
+// compress compresses b with c, prefixed with the compression header.
+func compress(c Compression, b []byte) ([]byte, error) {
+	header := []byte{compressionMagic, byte(c)}
+	switch c {
+	case Gzip:
+		var buf bytes.Buffer
+		buf.Write(header)
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Zstd:
+		enc, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(b, header), nil
+	case Snappy:
+		return append(header, s2.EncodeSnappy(nil, b)...), nil
+	}
+	return nil, fmt.Errorf("unknown compression %v", c)
+}
+
+// Decompress returns b decompressed when it starts with the compression header written by RedisCache, and b otherwise.
+func Decompress(b []byte) ([]byte, error) {
+	if len(b) < 2 || b[0] != compressionMagic {
+		return b, nil
+	}
+	c, data := Compression(b[1]), b[2:]
+	switch c {
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case Zstd:
+		dec, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(data, nil)
+	case Snappy:
+		return s2.Decode(nil, data)
+	}
+	return nil, fmt.Errorf("unknown compression %v", c)
+}
@@ -0,0 +1,70 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-redis/redismock/v9"
+	"reflect"
+	"testing"
+)
+
+func TestCompression_roundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible "), 1000)
+	for _, c := range []Compression{Gzip, Zstd, Snappy} {
+		t.Run(c.String(), func(t *testing.T) {
+			b, err := compress(c, data)
+			if err != nil {
+				t.Fatalf("compress() error = %v", err)
+			}
+			if b[0] != compressionMagic || Compression(b[1]) != c {
+				t.Errorf("compress() header = %x, want ce%02x", b[:2], byte(c))
+			}
+			if len(b) >= len(data) {
+				t.Errorf("compress() size = %v, want less than %v", len(b), len(data))
+			}
+			got, err := Decompress(b)
+			if err != nil || !bytes.Equal(got, data) {
+				t.Errorf("Decompress() = %v bytes, %v, want %v bytes", len(got), err, len(data))
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	raw := []byte(value1().toGob())
+	if got, err := Decompress(raw); err != nil || !bytes.Equal(got, raw) {
+		t.Errorf("Decompress() of uncompressed value = %v, %v, want it unchanged", got, err)
+	}
+	if _, err := Decompress([]byte{compressionMagic, 0x7f, 1, 2}); err == nil {
+		t.Errorf("Decompress() of unknown compression error = nil, want error")
+	}
+	if _, err := Decompress([]byte{compressionMagic, byte(Gzip), 1, 2}); err == nil {
+		t.Errorf("Decompress() of corrupt value error = nil, want error")
+	}
+}
+
+func TestRedisDriver_compression(t *testing.T) {
+	large := Value{Value: string(bytes.Repeat([]byte("x"), 1000))}
+	b, _ := GobCodec{}.Marshal(large)
+	compressed, _ := compress(Snappy, b)
+
+	r, mock := redismock.NewClientMock()
+	mock.ExpectHSet("test", key1().toGob(), string(compressed)).SetVal(1)
+	mock.ExpectHSet("test", key1().toGob(), value1().toGob()).SetVal(1)
+	mock.ExpectHGetAll("test").SetVal(map[string]string{key1().toGob(): string(compressed), key2().toGob(): value1().toGob()})
+	c := NewRedisCacheDriver[Key, Value]("test", r).SetCompression(Snappy, 500)
+
+	if !c.Set(context.Background(), key1(), large) {
+		t.Errorf("Set() of a large value = false, want true")
+	}
+	if !c.Set(context.Background(), key1(), value1()) {
+		t.Errorf("Set() of a small value = false, want true")
+	}
+	want := map[Key]Value{key1(): large, key2(): value1()}
+	if got := c.All(context.Background()); !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v entries, want %v", len(got), len(want))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package driver
+
+// Sizer is implemented by values able to estimate their size in bytes, used as their cost by the bounded memory
+// drivers when no CostFunc is set.
+type Sizer interface {
+	Size() int64
+}
+
+// CostFunc returns the cost of an item, typically its size in bytes.
+type CostFunc[K comparable, V any] func(key K, value V) int64
+
+// SizeOf estimates the size of v in bytes: the Size of values implementing Sizer, the length of strings and byte
+// slices, and 1 for any other value.
+func SizeOf(v any) int64 {
+	switch v := v.(type) {
+	case Sizer:
+		return v.Size()
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	}
+	return 1
+}
+
+// costOf returns the cost of an item using f when set, and SizeOf the value otherwise.
+func costOf[K comparable, V any](f CostFunc[K, V], key K, value V) int64 {
+	if f != nil {
+		return max(f(key, value), 0)
+	}
+	return max(SizeOf(value), 0)
+}
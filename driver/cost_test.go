@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+type sized int64
+
+func (s sized) Size() int64 {
+	return int64(s)
+}
+
+func TestSizeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want int64
+	}{
+		{name: "sizer", v: sized(42), want: 42},
+		{name: "string", v: "abc", want: 3},
+		{name: "bytes", v: []byte("abcd"), want: 4},
+		{name: "other", v: struct{}{}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SizeOf(tt.v); got != tt.want {
+				t.Errorf("SizeOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	type costCache interface {
+		Cache[string, string]
+		Stats() EvictionStats
+	}
+	tests := []struct {
+		name  string
+		cache func() costCache
+	}{
+		{
+			name: "lru",
+			cache: func() costCache {
+				return NewLRUCache[string, string](math.MaxInt).SetMaxCost(100, nil)
+			},
+		},
+		{
+			name: "tinylfu",
+			cache: func() costCache {
+				return NewTinyLFUCache[string, string](10).SetMaxCost(100, nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.cache()
+			ctx := context.Background()
+			for _, k := range []string{"a", "b", "c", "d"} {
+				c.Get(ctx, k)
+				c.Set(ctx, k, string(make([]byte, 30)))
+			}
+			if got := c.Stats(); got.Cost > 100 || got.Cost != int64(got.Entries)*30 {
+				t.Errorf("Stats() = %+v, want cost of at most 100 and 30 per entry", got)
+			}
+			if c.Set(ctx, "huge", string(make([]byte, 101))) || c.Has(ctx, "huge") {
+				t.Errorf("Set() of an item costing more than the max cost stored it")
+			}
+			c.Clear(ctx)
+			if got := c.Stats().Cost; got != 0 {
+				t.Errorf("Stats().Cost after Clear() = %v, want 0", got)
+			}
+		})
+	}
+}
+
+func TestLRUCache_SetMaxCost(t *testing.T) {
+	var evicted []string
+	l := NewLRUCache[string, string](math.MaxInt).
+		SetMaxCost(10, func(k string, v string) int64 { return int64(len(k) + len(v)) }).
+		OnEvict(func(k string, _ string) { evicted = append(evicted, k) })
+	ctx := context.Background()
+	l.Set(ctx, "a", "1234")
+	l.Set(ctx, "b", "1234")
+	l.Set(ctx, "a", "12345678")
+	if got := l.Stats().Cost; got != 9 {
+		t.Errorf("Stats().Cost = %v, want 9", got)
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("evicted = %v, want [b]", evicted)
+	}
+}
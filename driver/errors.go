@@ -0,0 +1,44 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by CacheV2.Get when the key is not cached.
+var ErrNotFound = errors.New("key not found")
+
+// BackendError is returned by CacheV2 drivers when the backing store failed, such as a connection error or timeout,
+// as opposed to the key not being cached.
+type BackendError struct {
+	Op  string
+	Err error
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("cache driver %s failed: %v", e.Op, e.Err)
+}
+
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned by CacheV2 drivers when a stored item could not be decoded, for example because its type
+// has changed since it was stored.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cache driver could not decode item: %v", e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNamespaceTypes is returned by NewNamespace when the namespace already exists with different key or value types.
+var ErrNamespaceTypes = errors.New("namespace already exists with different types")
+
+// errV1Failed is the error of a v1 driver returning false from an operation, which does not say why.
+var errV1Failed = errors.New("operation returned false")
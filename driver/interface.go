@@ -10,3 +10,35 @@ type Cache[K comparable, V any] interface {
 	Delete(ctx context.Context, key K) bool
 	Clear(ctx context.Context) bool
 }
+
+// MetaStore is implemented by drivers able to persist named metadata, such as a delta cursor, alongside the records.
+type MetaStore interface {
+	GetMeta(ctx context.Context, name string) (string, bool)
+	SetMeta(ctx context.Context, name string, value string) bool
+}
+
+// TagIndex is implemented by drivers able to maintain an index of the keys carrying each tag, used for tag based
+// invalidation.
+type TagIndex[K comparable] interface {
+	AddTags(ctx context.Context, key K, tags []string) bool
+	RemoveTags(ctx context.Context, key K, tags []string) bool
+	TaggedKeys(ctx context.Context, tag string) []K
+	ClearTags(ctx context.Context) bool
+}
+
+// Pinger is implemented by drivers able to check that their backing store is reachable, used by health checks.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// CacheV2 is a driver reporting why operations failed. Get returns ErrNotFound when the key is not cached, a
+// *BackendError when the backing store failed and a *DecodeError when the item could not be decoded. Use FromV1 and
+// ToV1 to adapt between Cache and CacheV2.
+type CacheV2[K comparable, V any] interface {
+	Has(ctx context.Context, key K) (bool, error)
+	Get(ctx context.Context, key K) (V, error)
+	All(ctx context.Context) (map[K]V, error)
+	Set(ctx context.Context, key K, value V) error
+	Delete(ctx context.Context, key K) error
+	Clear(ctx context.Context) error
+}
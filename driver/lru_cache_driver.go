@@ -0,0 +1,190 @@
+package driver
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LRUCache stores up to a maximum number of items in memory, and optionally up to a maximum total cost, evicting the
+// least recently used items when full. All operations are O(1), except All, and it is safe for concurrent use.
+type LRUCache[K comparable, V any] struct {
+	memoryMeta[K]
+	mu         sync.Mutex
+	maxEntries int
+	maxCost    int64
+	costFunc   CostFunc[K, V]
+	cost       int64
+	items      map[K]*list.Element
+	order      *list.List
+	onEvict    func(key K, value V)
+	hits       uint64
+	misses     uint64
+	evictions  uint64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+	cost  int64
+}
+
+// EvictionStats are the counters of a bounded memory driver since it was created.
+type EvictionStats struct {
+	Entries int
+	// Cost is the total cost of the items, which is their number unless a max cost is set.
+	Cost      int64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// NewLRUCache returns an LRUCache holding at most maxEntries items, which must be positive. Use math.MaxInt with
+// SetMaxCost to only bound the total cost.
+func NewLRUCache[K comparable, V any](maxEntries int) *LRUCache[K, V] {
+	if maxEntries <= 0 {
+		panic("lru cache max entries must be positive")
+	}
+	return &LRUCache[K, V]{
+		maxEntries: maxEntries,
+		items:      make(map[K]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// OnEvict sets a func called with every item evicted to make room for another. It is not called for items deleted or
+// cleared, and is called without holding the lock, so may use the cache.
+func (l *LRUCache[K, V]) OnEvict(f func(key K, value V)) *LRUCache[K, V] {
+	l.onEvict = f
+	return l
+}
+
+// SetMaxCost bounds the total cost of the items, as returned by f, or by the Size of values implementing Sizer when f
+// is nil. Items costing more than maxCost are not stored. Must be called before the cache is used.
+func (l *LRUCache[K, V]) SetMaxCost(maxCost int64, f CostFunc[K, V]) *LRUCache[K, V] {
+	l.maxCost = maxCost
+	l.costFunc = f
+	return l
+}
+
+// Stats returns a snapshot of the cache counters.
+func (l *LRUCache[K, V]) Stats() EvictionStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return EvictionStats{Entries: l.order.Len(), Cost: l.cost, Hits: l.hits, Misses: l.misses, Evictions: l.evictions}
+}
+
+// Has reports whether key is cached, without marking it as used.
+func (l *LRUCache[K, V]) Has(_ context.Context, key K) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.items[key]
+	return ok
+}
+
+// Get returns the item for key, marking it as the most recently used.
+func (l *LRUCache[K, V]) Get(_ context.Context, key K) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.items[key]
+	if !ok {
+		l.misses++
+		return *new(V), false
+	}
+	l.hits++
+	l.order.MoveToFront(e)
+	return e.Value.(*lruEntry[K, V]).value, true
+}
+
+// All returns a copy of the items, without marking them as used.
+func (l *LRUCache[K, V]) All(_ context.Context) map[K]V {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	all := make(map[K]V, len(l.items))
+	for k, e := range l.items {
+		all[k] = e.Value.(*lruEntry[K, V]).value
+	}
+	return all
+}
+
+// Set stores the item for key as the most recently used, evicting the least recently used items when full. It
+// returns false, leaving the key uncached, when the item alone costs more than the max cost.
+func (l *LRUCache[K, V]) Set(_ context.Context, key K, value V) bool {
+	cost := l.costOf(key, value)
+	l.mu.Lock()
+	e, ok := l.items[key]
+	if l.maxCost > 0 && cost > l.maxCost {
+		if ok {
+			l.removeElement(e)
+			l.untag(key)
+		}
+		l.mu.Unlock()
+		return false
+	}
+	if ok {
+		entry := e.Value.(*lruEntry[K, V])
+		l.cost += cost - entry.cost
+		entry.value, entry.cost = value, cost
+		l.order.MoveToFront(e)
+	} else {
+		l.items[key] = l.order.PushFront(&lruEntry[K, V]{key: key, value: value, cost: cost})
+		l.cost += cost
+	}
+	var evicted []*lruEntry[K, V]
+	for l.order.Len() > l.maxEntries || (l.maxCost > 0 && l.cost > l.maxCost) {
+		evicted = append(evicted, l.evictElement(l.order.Back()))
+	}
+	l.mu.Unlock()
+	l.evicted(evicted)
+	return true
+}
+
+func (l *LRUCache[K, V]) Delete(_ context.Context, key K) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.items[key]; ok {
+		l.removeElement(e)
+	}
+	return true
+}
+
+func (l *LRUCache[K, V]) Clear(_ context.Context) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.items = make(map[K]*list.Element)
+	l.order.Init()
+	l.cost = 0
+	return true
+}
+
+func (l *LRUCache[K, V]) removeElement(e *list.Element) *lruEntry[K, V] {
+	entry := l.order.Remove(e).(*lruEntry[K, V])
+	delete(l.items, entry.key)
+	l.cost -= entry.cost
+	return entry
+}
+
+// evictElement removes an item to make room for another, removing it from the tag index too.
+func (l *LRUCache[K, V]) evictElement(e *list.Element) *lruEntry[K, V] {
+	entry := l.removeElement(e)
+	l.untag(entry.key)
+	l.evictions++
+	return entry
+}
+
+// costOf returns the cost of an item, which is 1 unless a max cost is set.
+func (l *LRUCache[K, V]) costOf(key K, value V) int64 {
+	if l.maxCost <= 0 {
+		return 1
+	}
+	return costOf(l.costFunc, key, value)
+}
+
+func (l *LRUCache[K, V]) evicted(entries []*lruEntry[K, V]) {
+	if l.onEvict == nil {
+		return
+	}
+	for _, e := range entries {
+		l.onEvict(e.key, e.value)
+	}
+}
@@ -0,0 +1,158 @@
+package driver
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestLRUCache_eviction(t *testing.T) {
+	tests := []struct {
+		name        string
+		ops         func(l *LRUCache[int, string])
+		wantKeys    map[int]string
+		wantEvicted []int
+	}{
+		{
+			name: "evicts least recently set",
+			ops: func(l *LRUCache[int, string]) {
+				l.Set(context.Background(), 1, "one")
+				l.Set(context.Background(), 2, "two")
+				l.Set(context.Background(), 3, "three")
+			},
+			wantKeys:    map[int]string{2: "two", 3: "three"},
+			wantEvicted: []int{1},
+		},
+		{
+			name: "get marks as used",
+			ops: func(l *LRUCache[int, string]) {
+				l.Set(context.Background(), 1, "one")
+				l.Set(context.Background(), 2, "two")
+				l.Get(context.Background(), 1)
+				l.Set(context.Background(), 3, "three")
+			},
+			wantKeys:    map[int]string{1: "one", 3: "three"},
+			wantEvicted: []int{2},
+		},
+		{
+			name: "has does not mark as used",
+			ops: func(l *LRUCache[int, string]) {
+				l.Set(context.Background(), 1, "one")
+				l.Set(context.Background(), 2, "two")
+				l.Has(context.Background(), 1)
+				l.Set(context.Background(), 3, "three")
+			},
+			wantKeys:    map[int]string{2: "two", 3: "three"},
+			wantEvicted: []int{1},
+		},
+		{
+			name: "updating an item does not evict",
+			ops: func(l *LRUCache[int, string]) {
+				l.Set(context.Background(), 1, "one")
+				l.Set(context.Background(), 2, "two")
+				l.Set(context.Background(), 1, "uno")
+			},
+			wantKeys: map[int]string{1: "uno", 2: "two"},
+		},
+		{
+			name: "delete does not call on evict",
+			ops: func(l *LRUCache[int, string]) {
+				l.Set(context.Background(), 1, "one")
+				l.Delete(context.Background(), 1)
+				l.Set(context.Background(), 2, "two")
+			},
+			wantKeys: map[int]string{2: "two"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var evicted []int
+			l := NewLRUCache[int, string](2).OnEvict(func(k int, _ string) {
+				evicted = append(evicted, k)
+			})
+			tt.ops(l)
+			if got := l.All(context.Background()); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("All() = %v, want %v", got, tt.wantKeys)
+			}
+			if !reflect.DeepEqual(evicted, tt.wantEvicted) {
+				t.Errorf("evicted = %v, want %v", evicted, tt.wantEvicted)
+			}
+			if got := l.Stats().Evictions; got != uint64(len(tt.wantEvicted)) {
+				t.Errorf("Stats().Evictions = %v, want %v", got, len(tt.wantEvicted))
+			}
+		})
+	}
+}
+
+func TestLRUCache_Stats(t *testing.T) {
+	l := NewLRUCache[int, string](10)
+	l.Set(context.Background(), 1, "one")
+	l.Get(context.Background(), 1)
+	l.Get(context.Background(), 2)
+	if got, want := l.Stats(), (EvictionStats{Entries: 1, Cost: 1, Hits: 1, Misses: 1}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	l.Clear(context.Background())
+	if got := l.Stats().Entries; got != 0 {
+		t.Errorf("Stats().Entries after Clear() = %v, want 0", got)
+	}
+}
+
+func TestLRUCache_onEvictReentrant(t *testing.T) {
+	l := NewLRUCache[int, string](1)
+	l.OnEvict(func(k int, _ string) {
+		l.Has(context.Background(), k)
+	})
+	l.Set(context.Background(), 1, "one")
+	l.Set(context.Background(), 2, "two")
+}
+
+// TestLRUCache_concurrent is meaningful when run with the race detector.
+func TestLRUCache_concurrent(t *testing.T) {
+	l := NewLRUCache[int, int](50)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				l.Set(context.Background(), i, g)
+				l.Get(context.Background(), i-10)
+				if i%100 == 0 {
+					l.All(context.Background())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := l.Stats().Entries; got > 50 {
+		t.Errorf("Stats().Entries = %v, want at most 50", got)
+	}
+}
+
+func TestLRUCache_untagsEvicted(t *testing.T) {
+	ctx := context.Background()
+	l := NewLRUCache[int, string](2)
+	for i := 0; i < 1000; i++ {
+		l.Set(ctx, i, "v")
+		l.AddTags(ctx, i, []string{"all"})
+	}
+	got := l.TaggedKeys(ctx, "all")
+	slices.Sort(got)
+	if !reflect.DeepEqual(got, []int{998, 999}) {
+		t.Errorf("TaggedKeys() = %v, want [998 999]", got)
+	}
+	if n := len(l.tags.keys); n != 2 {
+		t.Errorf("reverse tag index holds %d keys, want 2", n)
+	}
+
+	c := NewLRUCache[int, string](10).SetMaxCost(3, nil)
+	c.Set(ctx, 1, "a")
+	c.AddTags(ctx, 1, []string{"all"})
+	c.Set(ctx, 1, "abcd")
+	if got := c.TaggedKeys(ctx, "all"); len(got) != 0 {
+		t.Errorf("TaggedKeys() after rejected Set = %v, want none", got)
+	}
+}
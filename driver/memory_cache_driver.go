@@ -1,42 +1,223 @@
 package driver
 
-import "context"
+import (
+	"context"
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+)
 
+// memoryCacheShards is the number of shards a MemoryCache is split into, each guarded by its own lock.
+const memoryCacheShards = 32
+
+// MemoryCache stores items in maps split into shards, and is safe for concurrent use.
 type MemoryCache[K comparable, V any] struct {
-	c map[K]V
+	memoryMeta[K]
+	shards [memoryCacheShards]memoryShard[K, V]
+	seed   maphash.Seed
+}
+
+type memoryShard[K comparable, V any] struct {
+	mu sync.RWMutex
+	c  map[K]V
 }
 
-func NewMemoryCache[K comparable, V any]() MemoryCache[K, V] {
-	return MemoryCache[K, V]{
-		c: make(map[K]V),
+func NewMemoryCache[K comparable, V any]() *MemoryCache[K, V] {
+	m := &MemoryCache[K, V]{seed: maphash.MakeSeed()}
+	for i := range m.shards {
+		m.shards[i].c = make(map[K]V)
 	}
+	return m
 }
 
-func (m MemoryCache[K, V]) Has(_ context.Context, key K) bool {
-	_, ok := m.c[key]
+func (m *MemoryCache[K, V]) Has(_ context.Context, key K) bool {
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.c[key]
 	return ok
 }
 
-func (m MemoryCache[K, V]) Get(_ context.Context, key K) (V, bool) {
-	v, ok := m.c[key]
+func (m *MemoryCache[K, V]) Get(_ context.Context, key K) (V, bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.c[key]
 	return v, ok
 }
 
-func (m MemoryCache[K, V]) All(_ context.Context) map[K]V {
-	return m.c
+// All returns a copy of the items. Shards are copied in turn, so items set or deleted concurrently may or may not be
+// included.
+func (m *MemoryCache[K, V]) All(_ context.Context) map[K]V {
+	all := make(map[K]V, m.len())
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		for k, v := range s.c {
+			all[k] = v
+		}
+		s.mu.RUnlock()
+	}
+	return all
+}
+
+func (m *MemoryCache[K, V]) Set(_ context.Context, key K, value V) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c[key] = value
+	return true
 }
 
-func (m MemoryCache[K, V]) Set(ctx context.Context, key K, value V) bool {
-	m.c[key] = value
-	return m.Has(ctx, key)
+func (m *MemoryCache[K, V]) Delete(_ context.Context, key K) bool {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.c, key)
+	return true
+}
+
+func (m *MemoryCache[K, V]) Clear(_ context.Context) bool {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.Lock()
+		s.c = make(map[K]V)
+		s.mu.Unlock()
+	}
+	return true
+}
+
+func (m *MemoryCache[K, V]) len() int {
+	n := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		n += len(s.c)
+		s.mu.RUnlock()
+	}
+	return n
 }
 
-func (m MemoryCache[K, V]) Delete(ctx context.Context, key K) bool {
-	delete(m.c, key)
-	return !m.Has(ctx, key)
+// shard returns the shard holding key.
+func (m *MemoryCache[K, V]) shard(key K) *memoryShard[K, V] {
+	return &m.shards[hashKey(m.seed, key)%memoryCacheShards]
+}
+
+// hashKey hashes key so that equal keys hash alike. Keys of basic types are hashed directly, and keys of other types,
+// such as structs, by reflection.
+func hashKey[K comparable](seed maphash.Seed, key K) uint64 {
+	if h, ok := hashBasic(seed, key); ok {
+		return h
+	}
+	var h maphash.Hash
+	h.SetSeed(seed)
+	hashValue(&h, reflect.ValueOf(&key).Elem())
+	return h.Sum64()
+}
+
+func hashBasic[K comparable](seed maphash.Seed, key K) (uint64, bool) {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(seed, k), true
+	case int:
+		return mixBits(uint64(k)), true
+	case int8:
+		return mixBits(uint64(k)), true
+	case int16:
+		return mixBits(uint64(k)), true
+	case int32:
+		return mixBits(uint64(k)), true
+	case int64:
+		return mixBits(uint64(k)), true
+	case uint:
+		return mixBits(uint64(k)), true
+	case uint8:
+		return mixBits(uint64(k)), true
+	case uint16:
+		return mixBits(uint64(k)), true
+	case uint32:
+		return mixBits(uint64(k)), true
+	case uint64:
+		return mixBits(k), true
+	case uintptr:
+		return mixBits(uint64(k)), true
+	case float64:
+		if k == 0 {
+			// +0 and -0 are equal keys, so must hash alike
+			k = 0
+		}
+		return mixBits(math.Float64bits(k)), true
+	case float32:
+		if k == 0 {
+			k = 0
+		}
+		return mixBits(uint64(math.Float32bits(k))), true
+	}
+	return 0, false
+}
+
+// hashValue writes a comparable value to h, field by field, so that equal values write the same bytes. Pointers and
+// channels are compared by address, so their address is written; interfaces are written with their dynamic type.
+func hashValue(h *maphash.Hash, v reflect.Value) {
+	var b [8]byte
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(b[:], u)
+		_, _ = h.Write(b[:])
+	}
+	writeFloat := func(f float64) {
+		if f == 0 {
+			// +0 and -0 are equal keys, so must hash alike
+			f = 0
+		}
+		writeUint(math.Float64bits(f))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		_, _ = h.WriteString(v.String())
+		_ = h.WriteByte(0)
+	case reflect.Bool:
+		if v.Bool() {
+			_ = h.WriteByte(1)
+		} else {
+			_ = h.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		writeFloat(real(v.Complex()))
+		writeFloat(imag(v.Complex()))
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint(uint64(v.Pointer()))
+	case reflect.Array:
+		for i := range v.Len() {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			hashValue(h, v.Field(i))
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			_ = h.WriteByte(0)
+			return
+		}
+		_, _ = h.WriteString(v.Elem().Type().String())
+		hashValue(h, v.Elem())
+	}
 }
 
-func (m MemoryCache[K, V]) Clear(_ context.Context) bool {
-	m.c = make(map[K]V)
-	return len(m.c) == 0
+// mixBits spreads sequential integers across shards (splitmix64 finaliser).
+func mixBits(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
 }
@@ -2,7 +2,10 @@ package driver
 
 import (
 	"context"
+	"hash/maphash"
 	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -35,9 +38,7 @@ func TestMemoryCache_All(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &MemoryCache[int, string]{
-				c: tt.fields.c,
-			}
+			m := newMemoryCacheWith(tt.fields.c)
 			if got := m.All(context.Background()); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("All() = %v, want %v", got, tt.want)
 			}
@@ -74,12 +75,13 @@ func TestMemoryCache_Clear(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &MemoryCache[int, string]{
-				c: tt.fields.c,
-			}
+			m := newMemoryCacheWith(tt.fields.c)
 			if got := m.Clear(context.Background()); got != tt.want {
 				t.Errorf("Clear() = %v, want %v", got, tt.want)
 			}
+			if got := m.len(); got != 0 {
+				t.Errorf("cache size after Clear() = %v, want 0", got)
+			}
 		})
 	}
 }
@@ -125,15 +127,13 @@ func TestMemoryCache_Delete(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &MemoryCache[int, string]{
-				c: tt.fields.c,
-			}
+			m := newMemoryCacheWith(tt.fields.c)
 			got := m.Delete(context.Background(), tt.args)
 			if got != tt.want {
 				t.Errorf("Delete() = %v, want %v", got, tt.want)
 			}
-			if !reflect.DeepEqual(len(m.c), tt.wantLen) {
-				t.Errorf("cache size = %v, wanted %v", len(m.c), tt.wantLen)
+			if !reflect.DeepEqual(m.len(), tt.wantLen) {
+				t.Errorf("cache size = %v, wanted %v", m.len(), tt.wantLen)
 			}
 		})
 	}
@@ -180,9 +180,7 @@ func TestMemoryCache_Get(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &MemoryCache[int, string]{
-				c: tt.fields.c,
-			}
+			m := newMemoryCacheWith(tt.fields.c)
 			got, got1 := m.Get(context.Background(), tt.args)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Get() got = %v, want %v", got, tt.want)
@@ -231,9 +229,7 @@ func TestMemoryCache_Has(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &MemoryCache[int, string]{
-				c: tt.fields.c,
-			}
+			m := newMemoryCacheWith(tt.fields.c)
 			if got := m.Has(context.Background(), tt.args); got != tt.want {
 				t.Errorf("Has() = %v, want %v", got, tt.want)
 			}
@@ -306,15 +302,13 @@ func TestMemoryCache_Set(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := &MemoryCache[int, string]{
-				c: tt.fields.c,
-			}
+			m := newMemoryCacheWith(tt.fields.c)
 			got := m.Set(context.Background(), tt.args.k, tt.args.v)
 			if got != tt.want {
 				t.Errorf("Set() = %v, want %v", got, tt.want)
 			}
-			if !reflect.DeepEqual(len(m.c), tt.wantLen) {
-				t.Errorf("cache size = %v, wanted %v", len(m.c), tt.wantLen)
+			if !reflect.DeepEqual(m.len(), tt.wantLen) {
+				t.Errorf("cache size = %v, wanted %v", m.len(), tt.wantLen)
 			}
 		})
 	}
@@ -331,9 +325,123 @@ func TestNewMemoryCache(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewMemoryCache[int, string](); !reflect.DeepEqual(got.c, tt.want) {
-				t.Errorf("NewMemoryCache() = %v, want %v", got.c, tt.want)
+			if got := NewMemoryCache[int, string]().All(context.Background()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewMemoryCache() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestMemoryCache_Meta(t *testing.T) {
+	m := NewMemoryCache[int, string]()
+	if _, ok := m.GetMeta(context.Background(), "cursor"); ok {
+		t.Errorf("GetMeta() on empty cache returned ok")
+	}
+	if !m.SetMeta(context.Background(), "cursor", "abc") {
+		t.Errorf("SetMeta() = false, want true")
+	}
+	if got, ok := m.GetMeta(context.Background(), "cursor"); !ok || got != "abc" {
+		t.Errorf("GetMeta() = %v, %v, want abc, true", got, ok)
+	}
+}
+
+func newMemoryCacheWith[K comparable, V any](c map[K]V) *MemoryCache[K, V] {
+	m := NewMemoryCache[K, V]()
+	for k, v := range c {
+		m.Set(context.Background(), k, v)
+	}
+	return m
+}
+
+func TestMemoryCache_All_copy(t *testing.T) {
+	m := newMemoryCacheWith(map[int]string{1: "one"})
+	all := m.All(context.Background())
+	all[2] = "two"
+	delete(all, 1)
+	if !m.Has(context.Background(), 1) || m.Has(context.Background(), 2) {
+		t.Errorf("modifying the map returned by All() modified the cache")
+	}
+}
+
+func TestMemoryCache_floatZero(t *testing.T) {
+	m := NewMemoryCache[float64, string]()
+	negZero := 0.0
+	negZero = -negZero
+	m.Set(context.Background(), 0.0, "zero")
+	if got, ok := m.Get(context.Background(), negZero); !ok || got != "zero" {
+		t.Errorf("Get(-0) = %v, %v, want zero, true", got, ok)
+	}
+}
+
+func TestHashKey_composite(t *testing.T) {
+	type inner struct {
+		F float64
+		I any
+	}
+	type key struct {
+		Id    string
+		N     int
+		Inner inner
+		P     *int
+	}
+	seed := maphash.MakeSeed()
+	p := new(int)
+	negZero := 0.0
+	negZero = -negZero
+	a := key{Id: "a", N: 1, Inner: inner{F: 0, I: 1}, P: p}
+	b := key{Id: "a", N: 1, Inner: inner{F: negZero, I: 1}, P: p}
+	if a != b || hashKey(seed, a) != hashKey(seed, b) {
+		t.Errorf("equal keys hash differently")
+	}
+	if hashKey(seed, a) == hashKey(seed, key{Id: "a", N: 1, Inner: inner{I: int64(1)}, P: p}) {
+		t.Errorf("keys with interface fields of different types hash alike")
+	}
+	if hashKey[any](seed, a) != hashKey[any](seed, b) {
+		t.Errorf("equal interface keys hash differently")
+	}
+
+	m := NewMemoryCache[key, int]()
+	shards := map[*memoryShard[key, int]]bool{}
+	for i := range 1000 {
+		k := key{Id: strconv.Itoa(i), N: i}
+		m.Set(context.Background(), k, i)
+		shards[m.shard(k)] = true
+	}
+	if len(shards) < memoryCacheShards/2 {
+		t.Errorf("struct keys spread across %v shards, want at least %v", len(shards), memoryCacheShards/2)
+	}
+	if got, ok := m.Get(context.Background(), key{Id: "42", N: 42}); !ok || got != 42 {
+		t.Errorf("Get() = %v, %v, want 42, true", got, ok)
+	}
+}
+
+// TestMemoryCache_concurrent is meaningful when run with the race detector.
+func TestMemoryCache_concurrent(t *testing.T) {
+	type key struct{ Id int }
+	ints := NewMemoryCache[int, int]()
+	structs := NewMemoryCache[key, int]()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			for i := range 500 {
+				ints.Set(ctx, i, g)
+				ints.Get(ctx, i)
+				structs.Set(ctx, key{i}, g)
+				structs.Has(ctx, key{i})
+				ints.SetMeta(ctx, "cursor", "abc")
+				ints.GetMeta(ctx, "cursor")
+				if i%100 == 0 {
+					ints.All(ctx)
+					structs.Delete(ctx, key{i})
+				}
+				if i%250 == 0 {
+					ints.Clear(ctx)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,67 @@
+package driver
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryMeta implements MetaStore and TagIndex in memory for the memory drivers which embed it. The zero value is
+// ready to use.
+type memoryMeta[K comparable] struct {
+	metaMu sync.RWMutex
+	meta   map[string]string
+	tagsMu sync.Mutex
+	tags   *MemoryTagIndex[K]
+}
+
+func (m *memoryMeta[K]) GetMeta(_ context.Context, name string) (string, bool) {
+	m.metaMu.RLock()
+	defer m.metaMu.RUnlock()
+	v, ok := m.meta[name]
+	return v, ok
+}
+
+func (m *memoryMeta[K]) SetMeta(_ context.Context, name string, value string) bool {
+	m.metaMu.Lock()
+	defer m.metaMu.Unlock()
+	if m.meta == nil {
+		m.meta = make(map[string]string)
+	}
+	m.meta[name] = value
+	return true
+}
+
+func (m *memoryMeta[K]) AddTags(ctx context.Context, key K, tags []string) bool {
+	return m.tagIndex().AddTags(ctx, key, tags)
+}
+
+func (m *memoryMeta[K]) RemoveTags(ctx context.Context, key K, tags []string) bool {
+	return m.tagIndex().RemoveTags(ctx, key, tags)
+}
+
+func (m *memoryMeta[K]) TaggedKeys(ctx context.Context, tag string) []K {
+	return m.tagIndex().TaggedKeys(ctx, tag)
+}
+
+func (m *memoryMeta[K]) ClearTags(ctx context.Context) bool {
+	return m.tagIndex().ClearTags(ctx)
+}
+
+func (m *memoryMeta[K]) tagIndex() *MemoryTagIndex[K] {
+	m.tagsMu.Lock()
+	defer m.tagsMu.Unlock()
+	if m.tags == nil {
+		m.tags = NewMemoryTagIndex[K]()
+	}
+	return m.tags
+}
+
+// untag removes key from the tag index, so that the index of a bounded driver does not keep the keys it evicted.
+func (m *memoryMeta[K]) untag(key K) {
+	m.tagsMu.Lock()
+	tags := m.tags
+	m.tagsMu.Unlock()
+	if tags != nil {
+		tags.removeKey(key)
+	}
+}
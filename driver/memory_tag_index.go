@@ -0,0 +1,89 @@
+package driver
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryTagIndex is an in memory TagIndex, safe for concurrent use.
+type MemoryTagIndex[K comparable] struct {
+	mu   sync.RWMutex
+	tags map[string]map[K]struct{}
+	// keys is the reverse of tags, so that a key can be removed from its tags without scanning every tag
+	keys map[K]map[string]struct{}
+}
+
+func NewMemoryTagIndex[K comparable]() *MemoryTagIndex[K] {
+	return &MemoryTagIndex[K]{
+		tags: make(map[string]map[K]struct{}),
+		keys: make(map[K]map[string]struct{}),
+	}
+}
+
+func (m *MemoryTagIndex[K]) AddTags(_ context.Context, key K, tags []string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tag := range tags {
+		keys, ok := m.tags[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			m.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+		keyTags, ok := m.keys[key]
+		if !ok {
+			keyTags = make(map[string]struct{})
+			m.keys[key] = keyTags
+		}
+		keyTags[tag] = struct{}{}
+	}
+	return true
+}
+
+func (m *MemoryTagIndex[K]) RemoveTags(_ context.Context, key K, tags []string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tag := range tags {
+		m.remove(key, tag)
+	}
+	return true
+}
+
+func (m *MemoryTagIndex[K]) TaggedKeys(_ context.Context, tag string) []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.tags[tag]))
+	for k := range m.tags[tag] {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (m *MemoryTagIndex[K]) ClearTags(_ context.Context) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tags = make(map[string]map[K]struct{})
+	m.keys = make(map[K]map[string]struct{})
+	return true
+}
+
+// removeKey removes key from every tag it carries, used by the bounded memory drivers to untag the items they evict.
+func (m *MemoryTagIndex[K]) removeKey(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for tag := range m.keys[key] {
+		m.remove(key, tag)
+	}
+}
+
+// remove removes key from tag, dropping the tag and the key from the index once empty. The caller must hold mu.
+func (m *MemoryTagIndex[K]) remove(key K, tag string) {
+	delete(m.tags[tag], key)
+	if len(m.tags[tag]) == 0 {
+		delete(m.tags, tag)
+	}
+	delete(m.keys[key], tag)
+	if len(m.keys[key]) == 0 {
+		delete(m.keys, key)
+	}
+}
@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMemoryTagIndex(t *testing.T) {
+	m := NewMemoryTagIndex[int]()
+	m.AddTags(context.Background(), 1, []string{"a", "b"})
+	m.AddTags(context.Background(), 2, []string{"a"})
+
+	got := m.TaggedKeys(context.Background(), "a")
+	slices.Sort(got)
+	if want := []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TaggedKeys() = %v, want %v", got, want)
+	}
+	m.RemoveTags(context.Background(), 1, []string{"a", "b"})
+	if got = m.TaggedKeys(context.Background(), "a"); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("TaggedKeys() after RemoveTags() = %v, want [2]", got)
+	}
+	if _, ok := m.tags["b"]; ok {
+		t.Errorf("empty tag not removed from index")
+	}
+	m.ClearTags(context.Background())
+	if got = m.TaggedKeys(context.Background(), "a"); len(got) != 0 {
+		t.Errorf("TaggedKeys() after ClearTags() = %v, want empty", got)
+	}
+}
+
+func TestMemoryTagIndex_removeKey(t *testing.T) {
+	m := NewMemoryTagIndex[int]()
+	m.AddTags(context.Background(), 1, []string{"a", "b"})
+	m.AddTags(context.Background(), 2, []string{"b", "c"})
+
+	m.removeKey(1)
+	if got := m.TaggedKeys(context.Background(), "b"); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("TaggedKeys() after removeKey() = %v, want [2]", got)
+	}
+	if _, ok := m.tags["a"]; ok {
+		t.Errorf("empty tag not removed from index")
+	}
+	if _, ok := m.keys[1]; ok {
+		t.Errorf("removed key kept in reverse index")
+	}
+	m.RemoveTags(context.Background(), 2, []string{"b", "c"})
+	if len(m.tags) != 0 || len(m.keys) != 0 {
+		t.Errorf("index not empty after RemoveTags(): tags = %v, keys = %v", m.tags, m.keys)
+	}
+}
@@ -0,0 +1,187 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Pool is a backing store shared by many namespaced caches, which can have different key and value types. A memory
+// pool keeps each namespace in its own map, and a Redis pool shares a single client, storing each namespace in a hash
+// at the pool prefix suffixed with ":<namespace>".
+type Pool struct {
+	mu         sync.Mutex
+	namespaces map[string]*namespace
+	client     *redis.Client
+	prefix     string
+}
+
+type namespace struct {
+	cache   any
+	clear   func(ctx context.Context) bool
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	sets    atomic.Uint64
+	deletes atomic.Uint64
+}
+
+// NamespaceStats are the counters of a namespace since it was created.
+type NamespaceStats struct {
+	Hits    uint64
+	Misses  uint64
+	Sets    uint64
+	Deletes uint64
+}
+
+func NewMemoryPool() *Pool {
+	return &Pool{namespaces: map[string]*namespace{}}
+}
+
+func NewRedisPool(prefix string, redisClient *redis.Client) *Pool {
+	return &Pool{namespaces: map[string]*namespace{}, client: redisClient, prefix: prefix}
+}
+
+// Namespaces returns the names of the namespaces in the pool, sorted.
+func (p *Pool) Namespaces() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.namespaces))
+	for name := range p.namespaces {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Stats returns the counters of the named namespace, or false if it does not exist.
+func (p *Pool) Stats(name string) (NamespaceStats, bool) {
+	ns, ok := p.namespace(name)
+	if !ok {
+		return NamespaceStats{}, false
+	}
+	return NamespaceStats{
+		Hits:    ns.hits.Load(),
+		Misses:  ns.misses.Load(),
+		Sets:    ns.sets.Load(),
+		Deletes: ns.deletes.Load(),
+	}, true
+}
+
+// Clear empties the named namespace without touching any other.
+func (p *Pool) Clear(ctx context.Context, name string) bool {
+	ns, ok := p.namespace(name)
+	return ok && ns.clear(ctx)
+}
+
+func (p *Pool) namespace(name string) (*namespace, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ns, ok := p.namespaces[name]
+	return ns, ok
+}
+
+// NamespacedCache is a Cache over a single namespace of a Pool.
+type NamespacedCache[K comparable, V any] struct {
+	c  Cache[K, V]
+	ns *namespace
+}
+
+// NewNamespace returns the cache for the named namespace of p, creating it if needed. Requesting an existing
+// namespace with different key or value types returns ErrNamespaceTypes.
+func NewNamespace[K comparable, V any](p *Pool, name string) (*NamespacedCache[K, V], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ns, ok := p.namespaces[name]; ok {
+		c, ok := ns.cache.(Cache[K, V])
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrNamespaceTypes, name)
+		}
+		return &NamespacedCache[K, V]{c: c, ns: ns}, nil
+	}
+	var c Cache[K, V]
+	if p.client != nil {
+		c = NewRedisCacheDriver[K, V](p.prefix+":"+name, p.client)
+	} else {
+		c = NewMemoryCache[K, V]()
+	}
+	ns := &namespace{cache: c, clear: c.Clear}
+	p.namespaces[name] = ns
+	return &NamespacedCache[K, V]{c: c, ns: ns}, nil
+}
+
+func (n *NamespacedCache[K, V]) Has(ctx context.Context, key K) bool {
+	return n.c.Has(ctx, key)
+}
+
+func (n *NamespacedCache[K, V]) Get(ctx context.Context, key K) (V, bool) {
+	v, ok := n.c.Get(ctx, key)
+	if ok {
+		n.ns.hits.Add(1)
+	} else {
+		n.ns.misses.Add(1)
+	}
+	return v, ok
+}
+
+func (n *NamespacedCache[K, V]) All(ctx context.Context) map[K]V {
+	return n.c.All(ctx)
+}
+
+func (n *NamespacedCache[K, V]) Set(ctx context.Context, key K, value V) bool {
+	n.ns.sets.Add(1)
+	return n.c.Set(ctx, key, value)
+}
+
+func (n *NamespacedCache[K, V]) Delete(ctx context.Context, key K) bool {
+	n.ns.deletes.Add(1)
+	return n.c.Delete(ctx, key)
+}
+
+func (n *NamespacedCache[K, V]) Clear(ctx context.Context) bool {
+	return n.c.Clear(ctx)
+}
+
+// Ping checks the backing store of the pool, and succeeds for a memory pool.
+func (n *NamespacedCache[K, V]) Ping(ctx context.Context) error {
+	if p, ok := n.c.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (n *NamespacedCache[K, V]) GetMeta(ctx context.Context, name string) (string, bool) {
+	if m, ok := n.c.(MetaStore); ok {
+		return m.GetMeta(ctx, name)
+	}
+	return "", false
+}
+
+func (n *NamespacedCache[K, V]) SetMeta(ctx context.Context, name string, value string) bool {
+	m, ok := n.c.(MetaStore)
+	return ok && m.SetMeta(ctx, name, value)
+}
+
+func (n *NamespacedCache[K, V]) AddTags(ctx context.Context, key K, tags []string) bool {
+	t, ok := n.c.(TagIndex[K])
+	return ok && t.AddTags(ctx, key, tags)
+}
+
+func (n *NamespacedCache[K, V]) RemoveTags(ctx context.Context, key K, tags []string) bool {
+	t, ok := n.c.(TagIndex[K])
+	return ok && t.RemoveTags(ctx, key, tags)
+}
+
+func (n *NamespacedCache[K, V]) TaggedKeys(ctx context.Context, tag string) []K {
+	if t, ok := n.c.(TagIndex[K]); ok {
+		return t.TaggedKeys(ctx, tag)
+	}
+	return nil
+}
+
+func (n *NamespacedCache[K, V]) ClearTags(ctx context.Context) bool {
+	t, ok := n.c.(TagIndex[K])
+	return ok && t.ClearTags(ctx)
+}
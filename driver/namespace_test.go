@@ -0,0 +1,76 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redismock/v9"
+	"reflect"
+	"testing"
+)
+
+func TestPool_memoryNamespaces(t *testing.T) {
+	p := NewMemoryPool()
+	users, err := NewNamespace[int, string](p, "users")
+	if err != nil {
+		t.Fatalf("NewNamespace(users) error = %v", err)
+	}
+	prices, err := NewNamespace[string, float64](p, "prices")
+	if err != nil {
+		t.Fatalf("NewNamespace(prices) error = %v", err)
+	}
+
+	users.Set(context.Background(), 1, "alice")
+	prices.Set(context.Background(), "apple", 0.5)
+	users.Get(context.Background(), 1)
+	users.Get(context.Background(), 2)
+
+	if got := users.All(context.Background()); !reflect.DeepEqual(got, map[int]string{1: "alice"}) {
+		t.Errorf("users.All() = %v, want map[1:alice]", got)
+	}
+	if got := prices.All(context.Background()); !reflect.DeepEqual(got, map[string]float64{"apple": 0.5}) {
+		t.Errorf("prices.All() = %v, want map[apple:0.5]", got)
+	}
+	if got, _ := p.Stats("users"); got != (NamespaceStats{Hits: 1, Misses: 1, Sets: 1}) {
+		t.Errorf("Stats(users) = %+v, want 1 hit, 1 miss and 1 set", got)
+	}
+	if got, _ := p.Stats("prices"); got != (NamespaceStats{Sets: 1}) {
+		t.Errorf("Stats(prices) = %+v, want 1 set", got)
+	}
+	if _, ok := p.Stats("missing"); ok {
+		t.Errorf("Stats(missing) returned ok")
+	}
+	if got := p.Namespaces(); !reflect.DeepEqual(got, []string{"prices", "users"}) {
+		t.Errorf("Namespaces() = %v, want [prices users]", got)
+	}
+	if again, err := NewNamespace[int, string](p, "users"); err != nil || !again.Has(context.Background(), 1) {
+		t.Errorf("NewNamespace() for existing namespace did not share its records")
+	}
+}
+
+func TestNewNamespace_typeMismatch(t *testing.T) {
+	p := NewMemoryPool()
+	if _, err := NewNamespace[int, string](p, "users"); err != nil {
+		t.Fatalf("NewNamespace() error = %v", err)
+	}
+	if c, err := NewNamespace[string, string](p, "users"); !errors.Is(err, ErrNamespaceTypes) || c != nil {
+		t.Errorf("NewNamespace() with different types = %v, %v, want ErrNamespaceTypes", c, err)
+	}
+}
+
+func TestPool_redisClear(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectDel("app:users").SetVal(1)
+	p := NewRedisPool("app", r)
+	_, _ = NewNamespace[int, string](p, "users")
+	_, _ = NewNamespace[int, string](p, "orders")
+
+	if !p.Clear(context.Background(), "users") {
+		t.Errorf("Clear(users) = false, want true")
+	}
+	if p.Clear(context.Background(), "missing") {
+		t.Errorf("Clear(missing) = true, want false")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
@@ -1,24 +1,47 @@
 package driver
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
 	"github.com/redis/go-redis/v9"
 )
 
 type RedisCache[K comparable, V any] struct {
-	c   *redis.Client
-	key string
+	c          *redis.Client
+	key        string
+	keyCodec   Codec
+	valueCodec Codec
+	// compression compresses encoded values of at least minCompressSize bytes.
+	compression     Compression
+	minCompressSize int
+}
+
+// SetKeyCodec sets the codec used to encode keys as hash fields. Defaults to GobCodec.
+func (r *RedisCache[K, V]) SetKeyCodec(c Codec) *RedisCache[K, V] {
+	r.keyCodec = c
+	return r
+}
+
+// SetValueCodec sets the codec used to encode values. Defaults to GobCodec.
+func (r *RedisCache[K, V]) SetValueCodec(c Codec) *RedisCache[K, V] {
+	r.valueCodec = c
+	return r
+}
+
+// SetCompression compresses encoded values of at least minSize bytes with c. Values are stored with a header byte
+// naming the algorithm, so compressed values are read whatever the current compression, and values written before
+// compression was enabled are still read uncompressed.
+func (r *RedisCache[K, V]) SetCompression(c Compression, minSize int) *RedisCache[K, V] {
+	r.compression = c
+	r.minCompressSize = minSize
+	return r
 }
 
 func (r *RedisCache[K, V]) Has(ctx context.Context, key K) bool {
-	var k bytes.Buffer
-	err := gob.NewEncoder(&k).Encode(key)
+	k, err := r.encodeKey(key)
 	if err != nil {
 		return false
 	}
-	has := r.c.HExists(ctx, r.key, k.String())
+	has := r.c.HExists(ctx, r.key, k)
 	if has.Err() != nil {
 		return false
 	}
@@ -26,17 +49,15 @@ func (r *RedisCache[K, V]) Has(ctx context.Context, key K) bool {
 }
 
 func (r *RedisCache[K, V]) Get(ctx context.Context, key K) (V, bool) {
-	var k bytes.Buffer
-	err := gob.NewEncoder(&k).Encode(key)
+	k, err := r.encodeKey(key)
 	if err != nil {
 		return *new(V), false
 	}
-	b, err := r.c.HGet(ctx, r.key, k.String()).Bytes()
+	b, err := r.c.HGet(ctx, r.key, k).Bytes()
 	if err != nil {
 		return *new(V), false
 	}
-	var v V
-	err = gob.NewDecoder(bytes.NewReader(b)).Decode(&v)
+	v, err := r.decodeValue(b)
 	return v, err == nil
 }
 
@@ -44,13 +65,11 @@ func (r *RedisCache[K, V]) All(ctx context.Context) map[K]V {
 	m := map[K]V{}
 	all := r.c.HGetAll(ctx, r.key).Val()
 	for key, value := range all {
-		var k K
-		err := gob.NewDecoder(bytes.NewReader([]byte(key))).Decode(&k)
+		k, err := r.decodeKey(key)
 		if err != nil {
 			continue
 		}
-		var v V
-		err = gob.NewDecoder(bytes.NewReader([]byte(value))).Decode(&v)
+		v, err := r.decodeValue([]byte(value))
 		if err != nil {
 			continue
 		}
@@ -60,24 +79,21 @@ func (r *RedisCache[K, V]) All(ctx context.Context) map[K]V {
 }
 
 func (r *RedisCache[K, V]) Set(ctx context.Context, key K, value V) bool {
-	var k bytes.Buffer
-	err := gob.NewEncoder(&k).Encode(key)
+	k, err := r.encodeKey(key)
 	if err != nil {
 		return false
 	}
-	var v bytes.Buffer
-	err = gob.NewEncoder(&v).Encode(value)
+	v, err := r.encodeValue(value)
 	if err != nil {
 		return false
 	}
-	err = r.c.HSet(ctx, r.key, k.String(), v.String()).Err()
+	err = r.c.HSet(ctx, r.key, k, string(v)).Err()
 	return err == nil
 }
 
 func (r *RedisCache[K, V]) Delete(ctx context.Context, key K) bool {
-	var k bytes.Buffer
-	err := gob.NewEncoder(&k).Encode(key)
-	r.c.HDel(ctx, r.key, k.String())
+	k, err := r.encodeKey(key)
+	r.c.HDel(ctx, r.key, k)
 	return err == nil
 }
 
@@ -86,6 +102,139 @@ func (r *RedisCache[K, V]) Clear(ctx context.Context) bool {
 	return err == nil
 }
 
+// Ping checks that Redis is reachable.
+func (r *RedisCache[K, V]) Ping(ctx context.Context) error {
+	return r.c.Ping(ctx).Err()
+}
+
+// GetMeta reads metadata from a separate hash stored at the cache key suffixed with ":meta".
+func (r *RedisCache[K, V]) GetMeta(ctx context.Context, name string) (string, bool) {
+	v, err := r.c.HGet(ctx, r.metaKey(), name).Result()
+	return v, err == nil
+}
+
+func (r *RedisCache[K, V]) SetMeta(ctx context.Context, name string, value string) bool {
+	return r.c.HSet(ctx, r.metaKey(), name, value).Err() == nil
+}
+
+func (r *RedisCache[K, V]) metaKey() string {
+	return r.key + ":meta"
+}
+
+// AddTags adds the key to a set per tag, stored at the cache key suffixed with ":tag:<tag>". The names of all tags are
+// kept in a set at the cache key suffixed with ":tags" so that they can be cleared.
+func (r *RedisCache[K, V]) AddTags(ctx context.Context, key K, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	k, err := r.encodeKey(key)
+	if err != nil {
+		return false
+	}
+	names := make([]any, 0, len(tags))
+	pipe := r.c.Pipeline()
+	for _, tag := range tags {
+		pipe.SAdd(ctx, r.tagKey(tag), k)
+		names = append(names, tag)
+	}
+	pipe.SAdd(ctx, r.tagsKey(), names...)
+	_, err = pipe.Exec(ctx)
+	return err == nil
+}
+
+func (r *RedisCache[K, V]) RemoveTags(ctx context.Context, key K, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	k, err := r.encodeKey(key)
+	if err != nil {
+		return false
+	}
+	pipe := r.c.Pipeline()
+	for _, tag := range tags {
+		pipe.SRem(ctx, r.tagKey(tag), k)
+	}
+	_, err = pipe.Exec(ctx)
+	return err == nil
+}
+
+func (r *RedisCache[K, V]) TaggedKeys(ctx context.Context, tag string) []K {
+	members := r.c.SMembers(ctx, r.tagKey(tag)).Val()
+	keys := make([]K, 0, len(members))
+	for _, member := range members {
+		k, err := r.decodeKey(member)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (r *RedisCache[K, V]) ClearTags(ctx context.Context) bool {
+	tags, err := r.c.SMembers(ctx, r.tagsKey()).Result()
+	if err != nil {
+		return false
+	}
+	keys := make([]string, 0, len(tags)+1)
+	for _, tag := range tags {
+		keys = append(keys, r.tagKey(tag))
+	}
+	keys = append(keys, r.tagsKey())
+	return r.c.Del(ctx, keys...).Err() == nil
+}
+
+func (r *RedisCache[K, V]) tagKey(tag string) string {
+	return r.key + ":tag:" + tag
+}
+
+func (r *RedisCache[K, V]) tagsKey() string {
+	return r.key + ":tags"
+}
+
+func (r *RedisCache[K, V]) encodeKey(key K) (string, error) {
+	b, err := r.keys().Marshal(key)
+	return string(b), err
+}
+
+func (r *RedisCache[K, V]) decodeKey(field string) (K, error) {
+	var k K
+	err := r.keys().Unmarshal([]byte(field), &k)
+	return k, err
+}
+
+func (r *RedisCache[K, V]) encodeValue(value V) ([]byte, error) {
+	b, err := r.values().Marshal(value)
+	if err != nil || r.compression == NoCompression || len(b) < r.minCompressSize {
+		return b, err
+	}
+	return compress(r.compression, b)
+}
+
+func (r *RedisCache[K, V]) decodeValue(b []byte) (V, error) {
+	var v V
+	b, err := Decompress(b)
+	if err != nil {
+		return v, err
+	}
+	err = r.values().Unmarshal(b, &v)
+	return v, err
+}
+
+func (r *RedisCache[K, V]) keys() Codec {
+	if r.keyCodec == nil {
+		return GobCodec{}
+	}
+	return r.keyCodec
+}
+
+func (r *RedisCache[K, V]) values() Codec {
+	if r.valueCodec == nil {
+		return GobCodec{}
+	}
+	return r.valueCodec
+}
+
 func NewRedisCacheDriver[K comparable, V any](redisKey string, redisClient *redis.Client) *RedisCache[K, V] {
 	return &RedisCache[K, V]{
 		c:   redisClient,
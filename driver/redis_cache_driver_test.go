@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redismock/v9"
 	"github.com/redis/go-redis/v9"
@@ -432,3 +433,109 @@ func key2() Key {
 func key1() Key {
 	return Key{Id: "key_1"}
 }
+
+func TestRedisDriver_Meta(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectHSet("test:meta", "cursor", "abc").SetVal(1)
+	mock.ExpectHGet("test:meta", "cursor").SetVal("abc")
+	mock.ExpectHGet("test:meta", "missing").RedisNil()
+	c := RedisCache[Key, Value]{c: r, key: "test"}
+
+	if !c.SetMeta(context.Background(), "cursor", "abc") {
+		t.Errorf("SetMeta() = false, want true")
+	}
+	if got, ok := c.GetMeta(context.Background(), "cursor"); !ok || got != "abc" {
+		t.Errorf("GetMeta() = %v, %v, want abc, true", got, ok)
+	}
+	if _, ok := c.GetMeta(context.Background(), "missing"); ok {
+		t.Errorf("GetMeta() for missing name returned ok")
+	}
+}
+
+func TestRedisDriver_Tags(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectSAdd("test:tag:a", key1().toGob()).SetVal(1)
+	mock.ExpectSAdd("test:tag:b", key1().toGob()).SetVal(1)
+	mock.ExpectSAdd("test:tags", "a", "b").SetVal(2)
+	mock.ExpectSMembers("test:tag:a").SetVal([]string{key1().toGob()})
+	mock.ExpectSRem("test:tag:a", key1().toGob()).SetVal(1)
+	mock.ExpectSMembers("test:tags").SetVal([]string{"a", "b"})
+	mock.ExpectDel("test:tag:a", "test:tag:b", "test:tags").SetVal(3)
+	c := RedisCache[Key, Value]{c: r, key: "test"}
+
+	if !c.AddTags(context.Background(), key1(), []string{"a", "b"}) {
+		t.Errorf("AddTags() = false, want true")
+	}
+	if got := c.TaggedKeys(context.Background(), "a"); !reflect.DeepEqual(got, []Key{key1()}) {
+		t.Errorf("TaggedKeys() = %v, want %v", got, []Key{key1()})
+	}
+	if !c.RemoveTags(context.Background(), key1(), []string{"a"}) {
+		t.Errorf("RemoveTags() = false, want true")
+	}
+	if !c.ClearTags(context.Background()) {
+		t.Errorf("ClearTags() = false, want true")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestRedisDriver_Ping(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectPing().SetVal("PONG")
+	mock.ExpectPing().SetErr(fmt.Errorf("refused"))
+	c := RedisCache[Key, Value]{c: r, key: "test"}
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v, want nil", err)
+	}
+	if err := c.Ping(context.Background()); err == nil {
+		t.Errorf("Ping() error = nil, want error")
+	}
+}
+
+func TestRedisDriverV2_Get(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectHGet("test", key1().toGob()).SetVal(value1().toGob())
+	mock.ExpectHGet("test", key1().toGob()).RedisNil()
+	mock.ExpectHGet("test", key1().toGob()).SetErr(fmt.Errorf("refused"))
+	mock.ExpectHGet("test", key1().toGob()).SetVal("not gob")
+	c := &RedisCacheV2[Key, Value]{&RedisCache[Key, Value]{c: r, key: "test"}}
+
+	if got, err := c.Get(context.Background(), key1()); err != nil || !reflect.DeepEqual(got, value1()) {
+		t.Errorf("Get() = %v, %v, want %v, nil", got, err, value1())
+	}
+	if _, err := c.Get(context.Background(), key1()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of missing key error = %v, want ErrNotFound", err)
+	}
+	var backendErr *BackendError
+	if _, err := c.Get(context.Background(), key1()); !errors.As(err, &backendErr) {
+		t.Errorf("Get() with redis error = %v, want *BackendError", err)
+	}
+	var decodeErr *DecodeError
+	if _, err := c.Get(context.Background(), key1()); !errors.As(err, &decodeErr) {
+		t.Errorf("Get() of undecodable item error = %v, want *DecodeError", err)
+	}
+}
+
+func TestRedisDriverV2_writes(t *testing.T) {
+	r, mock := redismock.NewClientMock()
+	mock.ExpectHSet("test", key1().toGob(), value1().toGob()).SetErr(fmt.Errorf("refused"))
+	mock.ExpectHDel("test", key1().toGob()).SetVal(1)
+	mock.ExpectDel("test").SetErr(fmt.Errorf("refused"))
+	c := &RedisCacheV2[Key, Value]{&RedisCache[Key, Value]{c: r, key: "test"}}
+
+	var backendErr *BackendError
+	if err := c.Set(context.Background(), key1(), value1()); !errors.As(err, &backendErr) {
+		t.Errorf("Set() error = %v, want *BackendError", err)
+	}
+	if err := c.Delete(context.Background(), key1()); err != nil {
+		t.Errorf("Delete() error = %v, want nil", err)
+	}
+	if err := c.Clear(context.Background()); !errors.As(err, &backendErr) {
+		t.Errorf("Clear() error = %v, want *BackendError", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
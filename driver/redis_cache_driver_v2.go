@@ -0,0 +1,104 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCacheV2 stores items in Redis like RedisCache, implementing CacheV2 so that connection errors, timeouts and
+// decode failures are reported rather than looking like misses. It also has the metadata, tag and ping methods of
+// RedisCache.
+type RedisCacheV2[K comparable, V any] struct {
+	*RedisCache[K, V]
+}
+
+func NewRedisCacheDriverV2[K comparable, V any](redisKey string, redisClient *redis.Client) *RedisCacheV2[K, V] {
+	return &RedisCacheV2[K, V]{NewRedisCacheDriver[K, V](redisKey, redisClient)}
+}
+
+func (r *RedisCacheV2[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	k, err := r.encodeKey(key)
+	if err != nil {
+		return false, fmt.Errorf("encode key: %w", err)
+	}
+	has, err := r.c.HExists(ctx, r.key, k).Result()
+	if err != nil {
+		return false, &BackendError{Op: "has", Err: err}
+	}
+	return has, nil
+}
+
+func (r *RedisCacheV2[K, V]) Get(ctx context.Context, key K) (V, error) {
+	k, err := r.encodeKey(key)
+	if err != nil {
+		return *new(V), fmt.Errorf("encode key: %w", err)
+	}
+	b, err := r.c.HGet(ctx, r.key, k).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return *new(V), ErrNotFound
+	}
+	if err != nil {
+		return *new(V), &BackendError{Op: "get", Err: err}
+	}
+	v, err := r.decodeValue(b)
+	if err != nil {
+		return *new(V), &DecodeError{Err: err}
+	}
+	return v, nil
+}
+
+// All returns every item which could be decoded, skipping the others.
+func (r *RedisCacheV2[K, V]) All(ctx context.Context) (map[K]V, error) {
+	all, err := r.c.HGetAll(ctx, r.key).Result()
+	if err != nil {
+		return nil, &BackendError{Op: "all", Err: err}
+	}
+	m := make(map[K]V, len(all))
+	for key, value := range all {
+		k, err := r.decodeKey(key)
+		if err != nil {
+			continue
+		}
+		v, err := r.decodeValue([]byte(value))
+		if err != nil {
+			continue
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func (r *RedisCacheV2[K, V]) Set(ctx context.Context, key K, value V) error {
+	k, err := r.encodeKey(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	v, err := r.encodeValue(value)
+	if err != nil {
+		return fmt.Errorf("encode value: %w", err)
+	}
+	if err := r.c.HSet(ctx, r.key, k, string(v)).Err(); err != nil {
+		return &BackendError{Op: "set", Err: err}
+	}
+	return nil
+}
+
+func (r *RedisCacheV2[K, V]) Delete(ctx context.Context, key K) error {
+	k, err := r.encodeKey(key)
+	if err != nil {
+		return fmt.Errorf("encode key: %w", err)
+	}
+	if err := r.c.HDel(ctx, r.key, k).Err(); err != nil {
+		return &BackendError{Op: "delete", Err: err}
+	}
+	return nil
+}
+
+func (r *RedisCacheV2[K, V]) Clear(ctx context.Context) error {
+	if err := r.c.Del(ctx, r.key).Err(); err != nil {
+		return &BackendError{Op: "clear", Err: err}
+	}
+	return nil
+}
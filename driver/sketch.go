@@ -0,0 +1,80 @@
+package driver
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+// sketchDepth is the number of rows of the count-min sketch, each indexed by a different hash of the key.
+const sketchDepth = 4
+
+// sketchMaxCount is the largest count of a counter, as frequencies only need to be compared among popular keys.
+const sketchMaxCount = 15
+
+// countMinSketch estimates how often keys have been seen recently. Counts are halved every time the number of
+// increments reaches the sample size, so that keys which are no longer popular age out.
+type countMinSketch[K comparable] struct {
+	seed       maphash.Seed
+	rows       [sketchDepth][]uint8
+	mask       uint64
+	increments int
+	sampleSize int
+}
+
+// newCountMinSketch returns a sketch sized for a cache of capacity items, with four counters per item in each row to
+// keep collisions rare.
+func newCountMinSketch[K comparable](capacity int) *countMinSketch[K] {
+	width := uint64(1) << bits.Len64(uint64(4*max(capacity, 16)-1))
+	s := &countMinSketch[K]{seed: maphash.MakeSeed(), mask: width - 1, sampleSize: 10 * max(capacity, 16)}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *countMinSketch[K]) increment(key K) {
+	h := s.hash(key)
+	for i := range s.rows {
+		if c := &s.rows[i][s.index(h, i)]; *c < sketchMaxCount {
+			*c++
+		}
+	}
+	if s.increments++; s.increments >= s.sampleSize {
+		s.reset()
+	}
+}
+
+func (s *countMinSketch[K]) estimate(key K) uint8 {
+	h := s.hash(key)
+	est := uint8(sketchMaxCount)
+	for i := range s.rows {
+		est = min(est, s.rows[i][s.index(h, i)])
+	}
+	return est
+}
+
+func (s *countMinSketch[K]) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] /= 2
+		}
+	}
+	s.increments /= 2
+}
+
+func (s *countMinSketch[K]) clear() {
+	for i := range s.rows {
+		clear(s.rows[i])
+	}
+	s.increments = 0
+}
+
+// index derives the counter of row i from the two halves of the hash (double hashing).
+func (s *countMinSketch[K]) index(h uint64, i int) uint64 {
+	return (h + uint64(i)*((h>>32)|1)) & s.mask
+}
+
+// hash hashes key with hashKey.
+func (s *countMinSketch[K]) hash(key K) uint64 {
+	return hashKey(s.seed, key)
+}
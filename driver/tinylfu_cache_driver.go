@@ -0,0 +1,286 @@
+package driver
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// tinyLFUWindowPercent is the share of the capacity given to the window, which admits every new item.
+const tinyLFUWindowPercent = 1
+
+// tinyLFUProtectedPercent is the share of the main region given to its protected segment.
+const tinyLFUProtectedPercent = 80
+
+type tinyLFURegion uint8
+
+const (
+	regionWindow tinyLFURegion = iota
+	regionProbation
+	regionProtected
+)
+
+type tinyLFUEntry[K comparable, V any] struct {
+	key    K
+	value  V
+	cost   int64
+	region tinyLFURegion
+}
+
+// TinyLFUCache stores up to a maximum number of items in memory using the W-TinyLFU policy, giving a higher hit ratio
+// than LRU for skewed workloads and resisting pollution by one-off scans. New items enter a small LRU window; items
+// leaving the window are only admitted to the main region, a segmented LRU, if they have been used more often recently
+// than the item they would evict, as estimated by a count-min sketch. It is safe for concurrent use.
+//
+// With a max cost, the window and main region are sized by the cost of their items rather than by their number.
+type TinyLFUCache[K comparable, V any] struct {
+	memoryMeta[K]
+	mu           sync.Mutex
+	items        map[K]*list.Element
+	maxEntries   int
+	lists        [3]*list.List
+	costs        [3]int64
+	windowCap    int64
+	mainCap      int64
+	protectedCap int64
+	maxCost      int64
+	costFunc     CostFunc[K, V]
+	sketch       *countMinSketch[K]
+	onEvict      func(key K, value V)
+	hits         uint64
+	misses       uint64
+	evictions    uint64
+}
+
+// NewTinyLFUCache returns a TinyLFUCache holding at most maxEntries items, which must be positive.
+func NewTinyLFUCache[K comparable, V any](maxEntries int) *TinyLFUCache[K, V] {
+	if maxEntries <= 0 {
+		panic("tinylfu cache max entries must be positive")
+	}
+	t := &TinyLFUCache[K, V]{
+		items:      make(map[K]*list.Element),
+		maxEntries: maxEntries,
+		lists:      [3]*list.List{list.New(), list.New(), list.New()},
+		sketch:     newCountMinSketch[K](maxEntries),
+	}
+	t.setCapacity(int64(maxEntries))
+	return t
+}
+
+// SetMaxCost bounds the total cost of the items, as returned by f, or by the Size of values implementing Sizer when f
+// is nil, instead of their number. maxEntries still sizes the frequency sketch, so should be an estimate of the number
+// of items. Items costing more than the main region are not stored. A max cost of 0 or less removes the cost limit,
+// bounding the number of items again. Must be called before the cache is used.
+func (t *TinyLFUCache[K, V]) SetMaxCost(maxCost int64, f CostFunc[K, V]) *TinyLFUCache[K, V] {
+	t.maxCost = maxCost
+	t.costFunc = f
+	if maxCost > 0 {
+		t.setCapacity(maxCost)
+	} else {
+		t.setCapacity(int64(t.maxEntries))
+	}
+	return t
+}
+
+func (t *TinyLFUCache[K, V]) setCapacity(capacity int64) {
+	t.windowCap = max(1, capacity*tinyLFUWindowPercent/100)
+	t.mainCap = capacity - t.windowCap
+	t.protectedCap = t.mainCap * tinyLFUProtectedPercent / 100
+}
+
+// OnEvict sets a func called with every item evicted or rejected by the admission filter to make room for another.
+// It is not called for items deleted or cleared, and is called without holding the lock, so may use the cache.
+func (t *TinyLFUCache[K, V]) OnEvict(f func(key K, value V)) *TinyLFUCache[K, V] {
+	t.onEvict = f
+	return t
+}
+
+// Stats returns a snapshot of the cache counters.
+func (t *TinyLFUCache[K, V]) Stats() EvictionStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return EvictionStats{Entries: len(t.items), Cost: t.costs[regionWindow] + t.costs[regionProbation] + t.costs[regionProtected], Hits: t.hits, Misses: t.misses, Evictions: t.evictions}
+}
+
+// Has reports whether key is cached, without recording a use.
+func (t *TinyLFUCache[K, V]) Has(_ context.Context, key K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.items[key]
+	return ok
+}
+
+// Get returns the item for key, recording a use.
+func (t *TinyLFUCache[K, V]) Get(_ context.Context, key K) (V, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sketch.increment(key)
+	e, ok := t.items[key]
+	if !ok {
+		t.misses++
+		return *new(V), false
+	}
+	t.hits++
+	t.touch(e)
+	return e.Value.(*tinyLFUEntry[K, V]).value, true
+}
+
+// All returns a copy of the items, without recording uses.
+func (t *TinyLFUCache[K, V]) All(_ context.Context) map[K]V {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	all := make(map[K]V, len(t.items))
+	for k, e := range t.items {
+		all[k] = e.Value.(*tinyLFUEntry[K, V]).value
+	}
+	return all
+}
+
+// Set stores the item for key in the window, evicting items when the cache is full. As with any admission policy,
+// the item may itself be evicted straight away if it is used less often than the items already cached. It returns
+// false, leaving the key uncached, when the item alone costs more than the main region.
+func (t *TinyLFUCache[K, V]) Set(_ context.Context, key K, value V) bool {
+	cost := t.costOf(key, value)
+	t.mu.Lock()
+	e, ok := t.items[key]
+	if t.maxCost > 0 && cost > t.mainCap {
+		if ok {
+			t.remove(e)
+			t.untag(key)
+		}
+		t.mu.Unlock()
+		return false
+	}
+	if ok {
+		entry := e.Value.(*tinyLFUEntry[K, V])
+		t.costs[entry.region] += cost - entry.cost
+		entry.value, entry.cost = value, cost
+		t.touch(e)
+	} else {
+		t.items[key] = t.lists[regionWindow].PushFront(&tinyLFUEntry[K, V]{key: key, value: value, cost: cost})
+		t.costs[regionWindow] += cost
+	}
+	evicted := t.evict()
+	// untagging under the lock only touches the tags of each evicted key, through the reverse tag index
+	for _, e := range evicted {
+		t.untag(e.key)
+	}
+	t.evictions += uint64(len(evicted))
+	t.mu.Unlock()
+	if t.onEvict != nil {
+		for _, e := range evicted {
+			t.onEvict(e.key, e.value)
+		}
+	}
+	return true
+}
+
+func (t *TinyLFUCache[K, V]) Delete(_ context.Context, key K) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.items[key]; ok {
+		t.remove(e)
+	}
+	return true
+}
+
+func (t *TinyLFUCache[K, V]) Clear(_ context.Context) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.items = make(map[K]*list.Element)
+	for i := range t.lists {
+		t.lists[i].Init()
+		t.costs[i] = 0
+	}
+	t.sketch.clear()
+	return true
+}
+
+// touch records a use of a cached item. Items used while on probation are promoted to the protected segment, which
+// demotes its least recently used items to probation when full.
+func (t *TinyLFUCache[K, V]) touch(e *list.Element) {
+	if entry := e.Value.(*tinyLFUEntry[K, V]); entry.region == regionProbation {
+		t.move(e, regionProtected)
+	} else {
+		t.lists[entry.region].MoveToFront(e)
+	}
+	for t.costs[regionProtected] > t.protectedCap && t.lists[regionProtected].Len() > 1 {
+		t.move(t.lists[regionProtected].Back(), regionProbation)
+	}
+}
+
+// evict moves the items leaving the window to the main region, and evicts items until each region is within its
+// capacity, returning the evicted items.
+func (t *TinyLFUCache[K, V]) evict() []*tinyLFUEntry[K, V] {
+	var evicted []*tinyLFUEntry[K, V]
+	for t.costs[regionWindow] > t.windowCap {
+		evicted = append(evicted, t.admit(t.lists[regionWindow].Back())...)
+	}
+	for t.mainCost() > t.mainCap {
+		victim := t.mainVictim()
+		if victim == nil {
+			break
+		}
+		evicted = append(evicted, t.remove(victim))
+	}
+	return evicted
+}
+
+// admit moves the candidate leaving the window to probation, if the main region has room or the candidate is used
+// more often than the item on probation which would be evicted first. It returns the evicted items, which is the
+// candidate when it is not admitted.
+func (t *TinyLFUCache[K, V]) admit(e *list.Element) []*tinyLFUEntry[K, V] {
+	candidate := e.Value.(*tinyLFUEntry[K, V])
+	if candidate.cost > t.mainCap {
+		return []*tinyLFUEntry[K, V]{t.remove(e)}
+	}
+	var evicted []*tinyLFUEntry[K, V]
+	if t.mainCost()+candidate.cost > t.mainCap {
+		victim := t.mainVictim()
+		if t.sketch.estimate(candidate.key) <= t.sketch.estimate(victim.Value.(*tinyLFUEntry[K, V]).key) {
+			return []*tinyLFUEntry[K, V]{t.remove(e)}
+		}
+		for t.mainCost()+candidate.cost > t.mainCap {
+			evicted = append(evicted, t.remove(t.mainVictim()))
+		}
+	}
+	t.move(e, regionProbation)
+	return evicted
+}
+
+// mainVictim returns the item of the main region to evict first, the least recently used on probation or, when
+// probation is empty, the least recently used protected item.
+func (t *TinyLFUCache[K, V]) mainVictim() *list.Element {
+	if victim := t.lists[regionProbation].Back(); victim != nil {
+		return victim
+	}
+	return t.lists[regionProtected].Back()
+}
+
+func (t *TinyLFUCache[K, V]) mainCost() int64 {
+	return t.costs[regionProbation] + t.costs[regionProtected]
+}
+
+// move moves an item to the front of region.
+func (t *TinyLFUCache[K, V]) move(e *list.Element, region tinyLFURegion) {
+	entry := t.lists[e.Value.(*tinyLFUEntry[K, V]).region].Remove(e).(*tinyLFUEntry[K, V])
+	t.costs[entry.region] -= entry.cost
+	entry.region = region
+	t.costs[region] += entry.cost
+	t.items[entry.key] = t.lists[region].PushFront(entry)
+}
+
+func (t *TinyLFUCache[K, V]) remove(e *list.Element) *tinyLFUEntry[K, V] {
+	entry := t.lists[e.Value.(*tinyLFUEntry[K, V]).region].Remove(e).(*tinyLFUEntry[K, V])
+	t.costs[entry.region] -= entry.cost
+	delete(t.items, entry.key)
+	return entry
+}
+
+// costOf returns the cost of an item, which is 1 unless a max cost is set.
+func (t *TinyLFUCache[K, V]) costOf(key K, value V) int64 {
+	if t.maxCost <= 0 {
+		return 1
+	}
+	return costOf(t.costFunc, key, value)
+}
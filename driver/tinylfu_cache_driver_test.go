@@ -0,0 +1,211 @@
+package driver
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTinyLFUCache_admission(t *testing.T) {
+	var evicted []int
+	c := NewTinyLFUCache[int, string](100).OnEvict(func(k int, _ string) {
+		evicted = append(evicted, k)
+	})
+	ctx := context.Background()
+	for k := range 100 {
+		c.Set(ctx, k, "hot")
+	}
+	for range 5 {
+		for k := range 100 {
+			c.Get(ctx, k)
+		}
+	}
+	// a scan of one-off keys must not displace the frequently used ones
+	for k := 1000; k < 2000; k++ {
+		c.Get(ctx, k)
+		c.Set(ctx, k, "scan")
+	}
+	hot := 0
+	for k := range 100 {
+		if c.Has(ctx, k) {
+			hot++
+		}
+	}
+	if hot < 95 {
+		t.Errorf("%v of 100 frequently used keys cached after scan, want at least 95", hot)
+	}
+	if got := c.Stats(); got.Entries > 100 || got.Evictions != uint64(len(evicted)) {
+		t.Errorf("Stats() = %+v, want at most 100 entries and %v evictions", got, len(evicted))
+	}
+}
+
+func TestTinyLFUCache_operations(t *testing.T) {
+	c := NewTinyLFUCache[int, string](10)
+	ctx := context.Background()
+	c.Set(ctx, 1, "one")
+	c.Set(ctx, 2, "two")
+	c.Set(ctx, 1, "uno")
+	if got, ok := c.Get(ctx, 1); !ok || got != "uno" {
+		t.Errorf("Get() = %v, %v, want uno, true", got, ok)
+	}
+	c.Delete(ctx, 2)
+	if got := c.All(ctx); !reflect.DeepEqual(got, map[int]string{1: "uno"}) {
+		t.Errorf("All() = %v, want map[1:uno]", got)
+	}
+	c.Clear(ctx)
+	if got := c.Stats().Entries; got != 0 {
+		t.Errorf("Stats().Entries after Clear() = %v, want 0", got)
+	}
+	tiny := NewTinyLFUCache[int, string](1)
+	tiny.Set(ctx, 1, "one")
+	tiny.Set(ctx, 2, "two")
+	if got := tiny.Stats().Entries; got != 1 {
+		t.Errorf("Stats().Entries with capacity 1 = %v, want 1", got)
+	}
+}
+
+func TestTinyLFUCache_untagsEvicted(t *testing.T) {
+	ctx := context.Background()
+	c := NewTinyLFUCache[int, string](10)
+	for k := range 1000 {
+		c.AddTags(ctx, k, []string{"all"})
+		c.Set(ctx, k, "v")
+	}
+	tagged := c.TaggedKeys(ctx, "all")
+	if len(tagged) != c.Stats().Entries {
+		t.Errorf("TaggedKeys() = %v keys, want the %v cached", len(tagged), c.Stats().Entries)
+	}
+	for _, k := range tagged {
+		if !c.Has(ctx, k) {
+			t.Errorf("TaggedKeys() contains %v, which is not cached", k)
+		}
+	}
+	if n := len(c.tags.keys); n != len(tagged) {
+		t.Errorf("reverse tag index holds %d keys, want %d", n, len(tagged))
+	}
+}
+
+func TestTinyLFUCache_SetMaxCostZero(t *testing.T) {
+	ctx := context.Background()
+	c := NewTinyLFUCache[int, string](10).SetMaxCost(0, nil)
+	for k := range 100 {
+		if !c.Set(ctx, k, "value") {
+			t.Fatalf("Set(%v) = false, want true", k)
+		}
+	}
+	if got := c.Stats().Entries; got != 10 {
+		t.Errorf("Stats().Entries with no max cost = %v, want 10", got)
+	}
+
+	// evict must stop once the main region is empty, even if it is still over capacity
+	c = NewTinyLFUCache[int, string](10)
+	c.mainCap = -1
+	c.Set(ctx, 1, "one")
+	c.Set(ctx, 2, "two")
+	if got := c.Stats().Entries; got != 1 {
+		t.Errorf("Stats().Entries with negative main capacity = %v, want 1", got)
+	}
+}
+
+// TestTinyLFUCache_concurrent is meaningful when run with the race detector.
+func TestTinyLFUCache_concurrent(t *testing.T) {
+	c := NewTinyLFUCache[int, int](50)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				c.Get(context.Background(), i%70)
+				c.Set(context.Background(), i%70, g)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := c.Stats().Entries; got > 50 {
+		t.Errorf("Stats().Entries = %v, want at most 50", got)
+	}
+}
+
+// boundedCache is implemented by the bounded memory drivers compared by the hit ratio tests.
+type boundedCache interface {
+	Cache[uint64, int]
+	Stats() EvictionStats
+}
+
+// zipfTrace returns n keys drawn from a Zipf distribution over keys keys, interleaved with scans of one-off keys every
+// scanEvery keys when scanEvery is positive.
+func zipfTrace(n int, keys uint64, scanEvery int) []uint64 {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.1, 1, keys-1)
+	trace := make([]uint64, 0, n)
+	scanKey := keys
+	for i := range n {
+		if scanEvery > 0 && i%scanEvery == 0 {
+			for range scanEvery / 2 {
+				trace = append(trace, scanKey)
+				scanKey++
+			}
+		}
+		trace = append(trace, z.Uint64())
+	}
+	return trace
+}
+
+// hitRatio replays trace as a RecordCache would, setting every missed key.
+func hitRatio(c boundedCache, trace []uint64) float64 {
+	ctx := context.Background()
+	for _, k := range trace {
+		if _, ok := c.Get(ctx, k); !ok {
+			c.Set(ctx, k, 0)
+		}
+	}
+	s := c.Stats()
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func TestTinyLFUCache_hitRatio(t *testing.T) {
+	tests := []struct {
+		name      string
+		scanEvery int
+	}{
+		{name: "zipf", scanEvery: 0},
+		{name: "zipf with scans", scanEvery: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := zipfTrace(100000, 100000, tt.scanEvery)
+			lru := hitRatio(NewLRUCache[uint64, int](1000), trace)
+			tinyLFU := hitRatio(NewTinyLFUCache[uint64, int](1000), trace)
+			t.Logf("hit ratio: lru %.3f, tinylfu %.3f", lru, tinyLFU)
+			if tinyLFU <= lru {
+				t.Errorf("tinylfu hit ratio %.3f, want above lru %.3f", tinyLFU, lru)
+			}
+		})
+	}
+}
+
+func BenchmarkLRUCache_zipf(b *testing.B) {
+	benchmarkZipf(b, NewLRUCache[uint64, int](1000))
+}
+
+func BenchmarkTinyLFUCache_zipf(b *testing.B) {
+	benchmarkZipf(b, NewTinyLFUCache[uint64, int](1000))
+}
+
+func benchmarkZipf(b *testing.B, c boundedCache) {
+	trace := zipfTrace(100000, 100000, 1000)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := range b.N {
+		k := trace[i%len(trace)]
+		if _, ok := c.Get(ctx, k); !ok {
+			c.Set(ctx, k, 0)
+		}
+	}
+	b.StopTimer()
+	s := c.Stats()
+	b.ReportMetric(float64(s.Hits)/float64(s.Hits+s.Misses), "hits/op")
+}
@@ -4,9 +4,11 @@ go 1.22
 
 require (
 	github.com/go-redis/redismock/v9 v9.2.0
+	github.com/klauspost/compress v1.18.0
 	github.com/redis/go-redis/v9 v9.5.1
 	github.com/robfig/cron/v3 v3.0.1
 	go.uber.org/zap v1.27.0
+	google.golang.org/protobuf v1.34.2
 )
 
 require (